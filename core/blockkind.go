@@ -0,0 +1,139 @@
+package core
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// blockKind the gameplay type of a world block (as named in the level data). the effects only apply to the player
+type blockKind string
+
+const (
+	// a regular block
+	blockKindSolid blockKind = "solid"
+	// slippery - slow to speed up & slow to stop
+	blockKindIce blockKind = "ice"
+	// launches the player back up on landing
+	blockKindBounce blockKind = "bounce"
+	// costs a life on touch
+	blockKindLava blockKind = "lava"
+	// costs a life on touch
+	blockKindSpikes blockKind = "spikes"
+	// carries the player along it's surface
+	blockKindConveyor blockKind = "conveyor"
+	// slows the player down & weakens jumps
+	blockKindSticky blockKind = "sticky"
+	// completes the level on touch
+	blockKindGoal blockKind = "goal"
+)
+
+// order the editor cycles through the block kinds in
+var blockKindNames = []blockKind{
+	blockKindSolid,
+	blockKindIce,
+	blockKindBounce,
+	blockKindLava,
+	blockKindSpikes,
+	blockKindConveyor,
+	blockKindSticky,
+	blockKindGoal,
+}
+
+// blockKindProperties the effects a kind of block has on the player
+type blockKindProperties struct {
+	material        string     // material of blocks of the kind that don't name one (so the kind can be read at a glance)
+	traction        float32    // scales acceleration & dampening while standing on the block (lower is more slippery)
+	speedLimit      float32    // scales max velocity while standing on the block
+	jumpScale       float32    // scales jump velocity when jumping off the block
+	bounceVelocity  float32    // vertical velocity given on landing on the block (0 for no bounce)
+	surfaceVelocity mgl32.Vec3 // velocity given to anything standing on the block (can be overridden per block)
+	isDeadly        bool       // costs a life on touch
+	isGoal          bool       // completes the level on touch
+}
+
+var blockKinds = map[blockKind]blockKindProperties{
+	blockKindSolid: {
+		material:   materialNameDefault,
+		traction:   1,
+		speedLimit: 1,
+		jumpScale:  1,
+	},
+	blockKindIce: {
+		material:   "ice",
+		traction:   0.1,
+		speedLimit: 1,
+		jumpScale:  1,
+	},
+	blockKindBounce: {
+		material:       "bounce",
+		traction:       1,
+		speedLimit:     1,
+		jumpScale:      1,
+		bounceVelocity: 1.5 * jumpVelocity,
+	},
+	blockKindLava: {
+		material:   "lava",
+		traction:   1,
+		speedLimit: 1,
+		jumpScale:  1,
+		isDeadly:   true,
+	},
+	blockKindSpikes: {
+		material:   "spikes",
+		traction:   1,
+		speedLimit: 1,
+		jumpScale:  1,
+		isDeadly:   true,
+	},
+	blockKindConveyor: {
+		material:        "conveyor",
+		traction:        1,
+		speedLimit:      1,
+		jumpScale:       1,
+		surfaceVelocity: mgl32.Vec3{4, 0, 0},
+	},
+	blockKindSticky: {
+		material:   "sticky",
+		traction:   1,
+		speedLimit: 0.4,
+		jumpScale:  0.6,
+	},
+	blockKindGoal: {
+		material:   "goal",
+		traction:   1,
+		speedLimit: 1,
+		jumpScale:  1,
+		isGoal:     true,
+	},
+}
+
+func isValidBlockKind(kind blockKind) bool {
+	_, isValid := blockKinds[kind]
+	return isValid
+}
+
+// blocks that don't name a kind are solid
+func orSolidBlockKind(kind blockKind) blockKind {
+	if kind == "" {
+		return blockKindSolid
+	}
+
+	return kind
+}
+
+// properties of the block being stood on (a solid block's if not standing on anything)
+func (state contactState) groundProperties() blockKindProperties {
+	if state.ground == nil {
+		return blockKinds[blockKindSolid]
+	}
+
+	return blockKinds[state.ground.kind]
+}
+
+// velocity the block being stood on carries things along at
+func (state contactState) groundVelocity() mgl32.Vec3 {
+	if state.ground == nil {
+		return mgl32.Vec3{}
+	}
+
+	return state.ground.surfaceVelocity
+}
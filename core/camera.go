@@ -11,6 +11,8 @@ const cameraRotateSpeed float32 = 5000
 
 type camera interface {
 	gameUpdatable
+	follow(target mgl32.Vec3)
+	reset()
 	getViewMatrix() mgl32.Mat4
 }
 
@@ -26,9 +28,19 @@ func (camera *arcballCamera) getViewMatrix() mgl32.Mat4 {
 	return mgl32.LookAtV(camera.eyePos, camera.lookAt, camera.up)
 }
 
-func (camera *arcballCamera) update(game *Game, dt float32, inputs map[GameInput]bool) {
-	player := game.player
+// resets the camera to it's initial orientation
+func (camera *arcballCamera) reset() {
+	camera.up = mgl32.Vec3{0.0, 1.0, 0.0}
+	camera.yaw = -45.0
+	camera.zoom = 1.0
+}
 
+func (camera *arcballCamera) follow(target mgl32.Vec3) {
+	camera.lookAt = target
+	camera.eyePos = computeEyePos(camera)
+}
+
+func (camera *arcballCamera) update(game *Game, dt float32, inputs map[GameInput]bool) {
 	var dyaw float32
 	if inputs[GameInputCameraRotateLeft] {
 		dyaw = cameraRotateSpeed / 1000.0
@@ -43,10 +55,8 @@ func (camera *arcballCamera) update(game *Game, dt float32, inputs map[GameInput
 		dzoom = cameraSpeed / 1000.0
 	}
 
-	camera.lookAt = player.pos
 	camera.yaw = camera.yaw + dyaw    // f32LimitBetween(, 1.5, 3.5)
 	camera.zoom = camera.zoom + dzoom // f32LimitBetween(, 1.0, 5.0)
-	camera.eyePos = computeEyePos(camera)
 
 	if game.IsEditModeEnabled {
 		game.Log += fmt.Sprintf("<br/>Camera: (zoom: %.2f\tyaw: %.2f)\n", camera.zoom, camera.yaw)
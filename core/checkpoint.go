@@ -0,0 +1,112 @@
+package core
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// lives the player starts a level with when the level doesn't say
+const defaultLives = 3
+
+var checkpointColor = mgl32.Vec4{1.0, 1.0, 1.0, 1.0}
+var checkpointColorActive = mgl32.Vec4{0.2, 0.9, 0.3, 1.0}
+
+// checkpoint a volume that once entered becomes where the player respawns (entering is detected by a trigger over the volume)
+type checkpoint struct {
+	pos   mgl32.Vec3
+	scale mgl32.Vec3
+	data  blockData // the volume as authored in the level
+}
+
+func (checkpoint *checkpoint) left() float32 {
+	return checkpoint.pos.X() + checkpoint.scale.X()
+}
+
+func (checkpoint *checkpoint) right() float32 {
+	return checkpoint.pos.X() - checkpoint.scale.X()
+}
+
+func (checkpoint *checkpoint) top() float32 {
+	return checkpoint.pos.Y() + checkpoint.scale.Y()
+}
+
+func (checkpoint *checkpoint) bottom() float32 {
+	return checkpoint.pos.Y() - checkpoint.scale.Y()
+}
+
+func (checkpoint *checkpoint) front() float32 {
+	return checkpoint.pos.Z() + checkpoint.scale.Z()
+}
+
+func (checkpoint *checkpoint) back() float32 {
+	return checkpoint.pos.Z() - checkpoint.scale.Z()
+}
+
+// Lives the number of lives the player has left
+func (game *Game) Lives() int {
+	return game.lives
+}
+
+// gives the player the level's starting lives & forgets any checkpoints they've reached
+func (game *Game) resetLives() {
+	game.lives = game.levelLives
+	if game.lives == 0 {
+		game.lives = defaultLives
+	}
+	game.checkpointIndex = -1
+}
+
+// makes a checkpoint the current one when the player enters it's volume
+func (game *Game) onTriggerEnterCheckpoint(event GameEvent) {
+	checkpoint := event.trigger.checkpoint
+	if checkpoint < 0 || event.Enemy >= 0 || checkpoint == game.checkpointIndex {
+		return
+	}
+
+	game.checkpointIndex = checkpoint
+	game.emit(GameEventCheckpoint)
+}
+
+// takes a life from the player - respawning them at the last checkpoint or ending the game if they're out of lives
+func (game *Game) onDeath(event GameEvent) {
+	game.lives--
+	game.stats.Deaths++
+	if game.lives <= 0 {
+		game.lives = 0
+		game.IsGameOver = true
+		game.emit(GameEventGameOver)
+		return
+	}
+
+	game.respawnPlayer()
+	game.emit(GameEventRespawn)
+}
+
+// sends the player back to the last checkpoint (or the level's spawn) & the enemies back to their starts
+func (game *Game) respawnPlayer() {
+	player := game.player
+
+	if game.checkpointIndex < 0 {
+		player.pos = getBlockPosFromData(game.spawn)
+	} else {
+		// standing on the bottom of the checkpoint's volume
+		checkpoint := game.checkpoints[game.checkpointIndex]
+		player.pos = mgl32.Vec3{checkpoint.pos.X(), checkpoint.bottom() + player.scale.Y(), checkpoint.pos.Z()}
+	}
+	player.prevPos = player.pos
+	player.vel = mgl32.Vec3{}
+	player.momentum = mgl32.Vec3{}
+	player.contacts = contactState{}
+
+	for _, enemy := range game.enemies {
+		enemy.reset()
+	}
+}
+
+func (checkpoint *checkpoint) render(game *Game, viewMatrix mgl32.Mat4, isActive bool) error {
+	color := checkpointColor
+	if isActive {
+		color = checkpointColorActive
+	}
+
+	return renderWireBox(game, viewMatrix, checkpoint.pos, checkpoint.scale, color)
+}
@@ -1,10 +1,23 @@
 package core
 
 import (
-	// "fmt"
+	"math"
+
 	"github.com/go-gl/mathgl/mgl32"
 )
 
+// max # of times a dynamic collidable's remaining motion is re-resolved (slid along the faces it hit) per step
+const maxCollisionPasses = 4
+
+// tolerance used so resting/sliding contacts aren't treated as penetrations due to floating point error
+const collisionEpsilon float32 = 1e-4
+
+// collisionContact a face of a world block hit while resolving a dynamic collidable's motion
+type collisionContact struct {
+	normal mgl32.Vec3 // normal of the face hit (pointing out of the world block)
+	block  *worldBlock
+}
+
 type collidable interface {
 	left() float32
 	right() float32
@@ -14,6 +27,127 @@ type collidable interface {
 	back() float32
 }
 
+// bounds an axis aligned bounding box
+type bounds struct {
+	min mgl32.Vec3
+	max mgl32.Vec3
+}
+
+func boundsOf(block collidable) bounds {
+	return bounds{
+		min: mgl32.Vec3{block.right(), block.bottom(), block.back()},
+		max: mgl32.Vec3{block.left(), block.top(), block.front()},
+	}
+}
+
+func (b bounds) left() float32 {
+	return b.max.X()
+}
+
+func (b bounds) right() float32 {
+	return b.min.X()
+}
+
+func (b bounds) top() float32 {
+	return b.max.Y()
+}
+
+func (b bounds) bottom() float32 {
+	return b.min.Y()
+}
+
+func (b bounds) front() float32 {
+	return b.max.Z()
+}
+
+func (b bounds) back() float32 {
+	return b.min.Z()
+}
+
+// grows the bounds to cover everything it passes through when moved by dPos
+func (b bounds) expand(dPos mgl32.Vec3) bounds {
+	return bounds{
+		min: mgl32.Vec3{b.min.X() + f32Min(0, dPos.X()), b.min.Y() + f32Min(0, dPos.Y()), b.min.Z() + f32Min(0, dPos.Z())},
+		max: mgl32.Vec3{b.max.X() + f32Max(0, dPos.X()), b.max.Y() + f32Max(0, dPos.Y()), b.max.Z() + f32Max(0, dPos.Z())},
+	}
+}
+
+func (b bounds) translate(dPos mgl32.Vec3) bounds {
+	return bounds{min: b.min.Add(dPos), max: b.max.Add(dPos)}
+}
+
+// checks to see if 2 collidables are overlapping or touching
+func checkForTouching(block1, block2 collidable) bool {
+	return block1.right() <= block2.left() &&
+		block1.left() >= block2.right() &&
+		block1.bottom() <= block2.top() &&
+		block1.top() >= block2.bottom() &&
+		block1.back() <= block2.front() &&
+		block1.front() >= block2.back()
+}
+
+// contactState the faces a dynamic collidable touched during it's last collision resolution
+type contactState struct {
+	grounded  bool // standing on top of a block
+	landed    bool // grounded this step but not the step before
+	ceiling   bool // hit the bottom of a block
+	wallLeft  bool // hit a block on it's left (+x) side
+	wallRight bool // hit a block on it's right (-x) side
+	wallFront bool // hit a block on it's front (+z) side
+	wallBack  bool // hit a block on it's back (-z) side
+
+	ground *worldBlock   // block being stood on (nil if not grounded)
+	blocks []*worldBlock // every block touched
+}
+
+func newContactState(contacts []collisionContact, previous contactState) contactState {
+	var state contactState
+	for _, contact := range contacts {
+		switch {
+		case contact.normal.Y() > 0:
+			state.grounded = true
+			if state.ground == nil {
+				state.ground = contact.block
+			}
+		case contact.normal.Y() < 0:
+			state.ceiling = true
+		case contact.normal.X() < 0:
+			state.wallLeft = true
+		case contact.normal.X() > 0:
+			state.wallRight = true
+		case contact.normal.Z() < 0:
+			state.wallFront = true
+		case contact.normal.Z() > 0:
+			state.wallBack = true
+		}
+
+		if !containsWorldBlock(state.blocks, contact.block) {
+			state.blocks = append(state.blocks, contact.block)
+		}
+	}
+
+	state.landed = state.grounded && !previous.grounded
+
+	return state
+}
+
+func (state contactState) touchingWall() bool {
+	return state.wallLeft || state.wallRight || state.wallFront || state.wallBack
+}
+
+// stops any vertical velocity into the ground or ceiling that was hit
+func (state contactState) clampVelocity(vel mgl32.Vec3) mgl32.Vec3 {
+	if state.grounded && vel.Y() < 0 {
+		vel[1] = 0
+	}
+
+	if state.ceiling && vel.Y() > 0 {
+		vel[1] = 0
+	}
+
+	return vel
+}
+
 // checks to see if 2 static collidables are colliding
 func checkForStaticOnStaticCollision(static1, static2 collidable) bool {
 	return static1.right() < static2.left() &&
@@ -24,94 +158,169 @@ func checkForStaticOnStaticCollision(static1, static2 collidable) bool {
 		static1.front() > static2.back()
 }
 
-// checks for a collision between a "dynamic" (moving) collidable (dPos = it's change in position) & a static collidable
-func checkForDynamicOnStaticCollision(dPos mgl32.Vec3, dynamic, static collidable) bool {
-	dRight := f32Max(0.0, -1*dPos.X())
-	if dynamic.right()-dRight >= static.left() {
-		return false
-	}
+// intersects the ray (origin + t*dir for t >= 0) with the bounds - returns the t the ray enters the bounds at & the normal of the
+// face it enters through (rays starting inside the bounds don't hit)
+func intersectRayWithBounds(origin, dir mgl32.Vec3, b bounds) (float32, mgl32.Vec3, bool) {
+	enterTime := float32(math.Inf(-1))
+	exitTime := float32(math.Inf(1))
+	enterAxis := -1
 
-	dLeft := f32Max(0.0, dPos.X())
-	if dynamic.left()+dLeft <= static.right() {
-		return false
-	}
+	for axis := 0; axis < 3; axis++ {
+		if dir[axis] == 0 {
+			if origin[axis] < b.min[axis] || origin[axis] > b.max[axis] {
+				return 0, mgl32.Vec3{}, false
+			}
 
-	dDown := f32Max(0.0, -1*dPos.Y())
-	if dynamic.bottom()-dDown >= static.top() {
-		return false
-	}
+			continue
+		}
+
+		axisEnterTime := (b.min[axis] - origin[axis]) / dir[axis]
+		axisExitTime := (b.max[axis] - origin[axis]) / dir[axis]
+		if axisEnterTime > axisExitTime {
+			axisEnterTime, axisExitTime = axisExitTime, axisEnterTime
+		}
 
-	dUp := f32Max(0.0, dPos.Y())
-	if dynamic.top()+dUp <= static.bottom() {
-		return false
+		if axisEnterTime > enterTime {
+			enterTime = axisEnterTime
+			enterAxis = axis
+		}
+		exitTime = f32Min(exitTime, axisExitTime)
 	}
 
-	dBackward := f32Max(0.0, -1*dPos.Z())
-	if dynamic.back()-dBackward >= static.front() {
-		return false
+	if enterAxis < 0 || enterTime < 0 || enterTime >= exitTime {
+		return 0, mgl32.Vec3{}, false
 	}
 
-	dForward := f32Max(0.0, dPos.Z())
-	if dynamic.front()+dForward <= static.back() {
-		return false
+	var normal mgl32.Vec3
+	if dir[enterAxis] > 0 {
+		normal[enterAxis] = -1
+	} else {
+		normal[enterAxis] = 1
 	}
 
-	return true
+	return enterTime, normal, true
 }
 
-// processes a collision between a "dynamic" (moving) collidable (dPos = it's change in position) & a static collidable
-func processDynamicOnStaticCollisionDetails(dt float32, dPos mgl32.Vec3, dynamic, static collidable) mgl32.Vec3 {
-	/*
-		Very simple collision correction:
+// sweeps the dynamic bounds along a single axis (moving d) against the static bounds - returns the times (as fractions of d) the
+// dynamic bounds start & stop overlapping the static bounds on this axis. If d is 0 the bounds either always or never overlap.
+func sweepAxis(dynamicMin, dynamicMax, staticMin, staticMax, d float32) (float32, float32, bool) {
+	if d == 0 {
+		if dynamicMax > staticMin+collisionEpsilon && dynamicMin < staticMax-collisionEpsilon {
+			return float32(math.Inf(-1)), float32(math.Inf(1)), true
+		}
+
+		return 0, 0, false
+	}
+
+	var entryDistance, exitDistance float32
+	if d > 0 {
+		entryDistance = staticMin - dynamicMax
+		exitDistance = staticMax - dynamicMin
+	} else {
+		entryDistance = staticMax - dynamicMin
+		exitDistance = staticMin - dynamicMax
+	}
 
-		STEP 1
-		Find out axis/side where the collision occurs. A collision occurs when blocks "intersect" on all 3 xyz axis. The last axis to intersect
-		during a collision tells you the axis/side/face on which the collision occurs. If more than one axis "intersect" last - then it's a
-		corner/edge collision
+	// resting/sliding contacts can be off by a little floating point error - treat them as touching exactly
+	if f32Abs(entryDistance) < collisionEpsilon {
+		entryDistance = 0
+	}
 
+	return entryDistance / d, exitDistance / d, true
+}
 
-		STEP 2
-		Adjust dPos only on the axis of collision to right before the collision happens. This creates a "slide" effect.
-	*/
+// sweeps the dynamic bounds by dPos against the static bounds - returns the time of impact (as a fraction [0, 1] of dPos) & the
+// normal of the static face that was hit
+func sweepBounds(dynamic bounds, dPos mgl32.Vec3, static bounds) (float32, mgl32.Vec3, bool) {
+	var entryTimes [3]float32
+	exitTime := float32(math.Inf(1))
 
-	var timeOfXAxisIntersection, timeOfYAxisIntersection, timeOfZAxisIntersection float32
-	var distanceToCollisionX, distanceToCollisionY, distanceToCollisionZ float32
+	for axis := 0; axis < 3; axis++ {
+		entryTime, axisExitTime, isOverlapping := sweepAxis(dynamic.min[axis], dynamic.max[axis], static.min[axis], static.max[axis], dPos[axis])
+		if !isOverlapping {
+			return 0, mgl32.Vec3{}, false
+		}
 
-	if dPos.X() > 0 {
-		distanceToCollisionX = static.right() - dynamic.left()
-		timeOfXAxisIntersection = dt * distanceToCollisionX / dPos.X()
-	} else if dPos.X() < 0 {
-		distanceToCollisionX = static.left() - dynamic.right()
-		timeOfXAxisIntersection = dt * distanceToCollisionX / dPos.X()
+		entryTimes[axis] = entryTime
+		exitTime = f32Min(exitTime, axisExitTime)
 	}
 
-	if dPos.Y() > 0 {
-		distanceToCollisionY = static.bottom() - dynamic.top()
-		timeOfYAxisIntersection = dt * distanceToCollisionY / dPos.Y()
-	} else if dPos.Y() < 0 {
-		distanceToCollisionY = static.top() - dynamic.bottom()
-		timeOfYAxisIntersection = dt * distanceToCollisionY / dPos.Y()
+	// the last axis to start overlapping is the axis of the face that was hit (on ties prefer landing on top of blocks)
+	hitAxis := 1
+	if entryTimes[0] > entryTimes[hitAxis] {
+		hitAxis = 0
 	}
-
-	if dPos.Z() > 0 {
-		distanceToCollisionZ = static.back() - dynamic.front()
-		timeOfZAxisIntersection = dt * distanceToCollisionZ / dPos.Z()
-	} else if dPos.Z() < 0 {
-		distanceToCollisionZ = static.front() - dynamic.back()
-		timeOfZAxisIntersection = dt * distanceToCollisionZ / dPos.Z()
+	if entryTimes[2] > entryTimes[hitAxis] {
+		hitAxis = 2
 	}
 
-	if timeOfXAxisIntersection >= timeOfYAxisIntersection && timeOfXAxisIntersection >= timeOfZAxisIntersection {
-		dPos[0] = distanceToCollisionX // if dX >= 0 then left side else right side
+	entryTime := entryTimes[hitAxis]
+	if entryTime < 0 || entryTime > 1 || entryTime >= exitTime {
+		return 0, mgl32.Vec3{}, false // already overlapping, not reached this step or only grazing an edge
 	}
 
-	if timeOfYAxisIntersection >= timeOfXAxisIntersection && timeOfYAxisIntersection >= timeOfZAxisIntersection {
-		dPos[1] = distanceToCollisionY
+	var normal mgl32.Vec3
+	if dPos[hitAxis] > 0 {
+		normal[hitAxis] = -1
+	} else {
+		normal[hitAxis] = 1
 	}
 
-	if timeOfZAxisIntersection >= timeOfXAxisIntersection && timeOfZAxisIntersection >= timeOfYAxisIntersection {
-		dPos[2] = distanceToCollisionZ
+	return entryTime, normal, true
+}
+
+// resolves the motion (dPos) of a "dynamic" (moving) collidable against static world blocks. The dynamic collidable is moved up
+// to the earliest hit, the rest of it's motion along the hit face's normal is removed (so it slides along the face) & the rest of
+// the motion is resolved again - up to maxCollisionPasses times. Returns the resolved motion & the faces that were hit.
+func resolveDynamicOnStaticCollisions(dPos mgl32.Vec3, dynamic collidable, statics []*worldBlock) (mgl32.Vec3, []collisionContact) {
+	dynamicBounds := boundsOf(dynamic)
+	contacts := make([]collisionContact, 0, 2)
+
+	var moved mgl32.Vec3
+	remaining := dPos
+
+	for pass := 0; pass < maxCollisionPasses; pass++ {
+		current := dynamicBounds.translate(moved)
+
+		hitTime := float32(math.Inf(1))
+		hits := make([]collisionContact, 0, 2)
+		for _, static := range statics {
+			time, normal, isHit := sweepBounds(current, remaining, boundsOf(static))
+			if !isHit || time > hitTime {
+				continue
+			}
+
+			if time < hitTime {
+				hitTime = time
+				hits = hits[:0]
+			}
+
+			hits = append(hits, collisionContact{normal: normal, block: static})
+		}
+
+		if len(hits) == 0 {
+			moved = moved.Add(remaining)
+			break
+		}
+
+		moved = moved.Add(remaining.Mul(hitTime))
+		remaining = remaining.Mul(1 - hitTime)
+
+		// slide - remove the motion into every face hit
+		for _, hit := range hits {
+			for axis := 0; axis < 3; axis++ {
+				if hit.normal[axis] != 0 {
+					remaining[axis] = 0
+				}
+			}
+		}
+
+		contacts = append(contacts, hits...)
+
+		if remaining == (mgl32.Vec3{}) {
+			break
+		}
 	}
 
-	return dPos
+	return moved, contacts
 }
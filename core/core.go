@@ -40,6 +40,41 @@ const (
 	GameInputEditModeCreateEnemy
 	// GameInputEditModeDelete input to delete all colliding blocks in edit mode
 	GameInputEditModeDelete
+	// GameInputEditModeAddWaypoint input to start a patrol path for the colliding enemy in edit mode (or add a waypoint to the
+	// path being placed)
+	GameInputEditModeAddWaypoint
+	// GameInputEditModeEndPath input to finish the patrol path being placed in edit mode
+	GameInputEditModeEndPath
+	// GameInputEditModeTogglePathMode input to switch a patrol path between looping & ping-ponging in edit mode
+	GameInputEditModeTogglePathMode
+	// GameInputEditModeCycleMaterial input to switch the material given to new world blocks in edit mode
+	GameInputEditModeCycleMaterial
+	// GameInputEditModeCycleBlockKind input to switch the kind given to new world blocks in edit mode
+	GameInputEditModeCycleBlockKind
+	// GameInputEditModeCycleMotion input to switch the motion given to new world blocks in edit mode
+	GameInputEditModeCycleMotion
+	// GameInputEditModeUndo input to undo the last change made in edit mode
+	GameInputEditModeUndo
+	// GameInputEditModeRedo input to redo the last change undone in edit mode
+	GameInputEditModeRedo
+	// GameInputEditModeSelect input to select the world block or enemy nearest the player in edit mode (or the next nearest if
+	// something is already selected). the movement keys then move the selection instead of the player
+	GameInputEditModeSelect
+	// GameInputEditModeCycleFace input to switch the movement keys between moving the selection & resizing each of it's faces
+	// in edit mode (only with a single world block or enemy selected)
+	GameInputEditModeCycleFace
+	// GameInputEditModeCycleGridSize input to switch the size of the grid new & moved blocks snap to in edit mode
+	GameInputEditModeCycleGridSize
+	// GameInputEditModeSelectMore input to add the world block or enemy nearest the player that isn't already selected to the
+	// selection in edit mode
+	GameInputEditModeSelectMore
+	// GameInputEditModeCopy input to copy the selection in edit mode
+	GameInputEditModeCopy
+	// GameInputEditModePaste input to paste what was last copied in edit mode (in the same place relative to the player as when
+	// it was copied)
+	GameInputEditModePaste
+	// GameInputEditModeDuplicate input to copy the selection in place (& select the copy) in edit mode
+	GameInputEditModeDuplicate
 )
 
 type gameUpdatable interface {
@@ -50,12 +85,33 @@ type gameRenderable interface {
 	render(game *Game, viewMatrix mgl32.Mat4) error
 }
 
+// inputs that act once per press rather than for as long as they're held
+var oneShotInputs = []GameInput{
+	GameInputEditModeToggle,
+	GameInputEditModeUndo,
+	GameInputEditModeRedo,
+	GameInputEditModeSelect,
+	GameInputEditModeCycleFace,
+	GameInputEditModeCycleGridSize,
+	GameInputEditModeSelectMore,
+	GameInputEditModeCopy,
+	GameInputEditModePaste,
+	GameInputEditModeDuplicate,
+}
+
 // maximum velocity for a moving object
 const maxVelocity float32 = 10
 const terminalVelocity float32 = 20
 const dampening float32 = 1
 
 const gravityAcceleration float32 = 1
+const jumpVelocity float32 = 20 * gravityAcceleration
+
+// length (ms) of a single simulation step - velocities/accelerations above are tuned per step (originally per 60fps frame)
+const fixedTimeStep float32 = 1000.0 / 60.0
+
+// max # of simulation steps run per Update (so a long frame - ex. a backgrounded tab - doesn't stall the game catching up)
+const maxStepsPerUpdate = 10
 
 // Game represents a game
 type Game struct {
@@ -63,6 +119,8 @@ type Game struct {
 	phongShader   ShaderProgram
 	gouraudShader ShaderProgram
 	blockMesh     Mesh
+	lineMesh      Mesh
+	boxLineMesh   Mesh
 
 	projMatrix      mgl32.Mat4
 	modelViewMatrix mgl32.Mat4
@@ -73,13 +131,40 @@ type Game struct {
 	lightPos mgl32.Vec3
 
 	player      *player
+	spawn       blockData               // where the player starts as authored in the level
+	checkpoints []*checkpoint           // volumes that become where the player respawns once reached
+	triggers    []*trigger              // the level's trigger volumes followed by the checkpoints' volumes
+	levelLives  int                     // lives the player starts with as authored in the level (0 for defaultLives)
+	materials   map[string]materialData // materials table of the level (see builtinMaterials for the rest)
+	prefabs     []prefabData            // groups of world blocks & enemies the editor can place (saved with the level)
 	enemies     []*enemy
 	worldBlocks []*worldBlock
+	worldIndex  *spatialHash // broadphase index over worldBlocks
+	navGraph    *navGraph    // walkable surfaces for enemy pathfinding (built lazily - see navigation())
 	camera      camera
 	editor      *gameEditor
 
+	time          float32 // simulation time (ms) since play started (held at 0 in edit mode) - drives the moving world blocks
+	accumulator   float32 // simulation time (ms) not yet consumed by a fixed step
+	interpolation float32 // fraction [0, 1) of a step between the last simulated state & the next one (used when rendering)
+	stepLog       string  // debug log written during the last simulation step
+
+	pendingInputs      map[GameInput]bool // one-shot inputs waiting to be applied on the next simulation step
+	editorHistoryDepth int                // # of editor changes that can be undone
+	levelJSON          string             // json of the last level imported
+	recording          *Replay            // replay currently being recorded (nil if not recording)
+	playback           *replayPlayback    // replay currently being played back (nil if not replaying)
+
+	isNavGraphDirty bool // whether world blocks have changed since the navGraph was built
+
+	lives           int       // lives the player has left
+	checkpointIndex int       // index of the last checkpoint the player reached (-1 for none - respawn at the spawn)
+	eventBus        eventBus  // dispatches events to the game's own systems & the host's subscribers
+	stats           GameStats // statistics of the current run
+
 	IsEditModeEnabled bool
 	IsGameOver        bool
+	IsLevelComplete   bool
 
 	Log string
 }
@@ -95,18 +180,20 @@ func NewGame(glCtx GlContext) (*Game, error) {
 
 	game.player = new(player)
 	game.player.scale = mgl32.Vec3{0.5, 0.5, 0.5}
+	game.spawn = newBlockData(game.player)
+	game.resetLives()
+	game.resetStats()
 
 	// generate world blocks
 	game.worldBlocks = make([]*worldBlock, 0, 100)
+	game.worldIndex = newSpatialHash(spatialHashCellSize)
 
 	// generate enemies
 	game.enemies = make([]*enemy, 0, 20)
 
 	// create a camera
 	arcballCamera := new(arcballCamera)
-	arcballCamera.up = mgl32.Vec3{0.0, 1.0, 0.0}
-	arcballCamera.yaw = -45.0
-	arcballCamera.zoom = 1.0
+	arcballCamera.reset()
 	game.camera = arcballCamera
 
 	// setup shaders/matrices/meshes
@@ -139,23 +226,71 @@ func NewGame(glCtx GlContext) (*Game, error) {
 		return nil, err
 	}
 
+	game.lineMesh, err = game.gl.NewMesh(lineVerticies[:], lineNormals[:], lineIndicies[:])
+	if err != nil {
+		return nil, err
+	}
+
+	game.boxLineMesh, err = game.gl.NewMesh(boxLineVerticies[:], boxLineNormals[:], boxLineIndicies[:])
+	if err != nil {
+		return nil, err
+	}
+
 	// setup edit mode
 	game.IsEditModeEnabled = false
 	game.editor = new(gameEditor)
+	game.editorHistoryDepth = defaultEditorHistoryDepth
+	game.pendingInputs = make(map[GameInput]bool)
+
+	// hook up the systems that react to events
+	game.Subscribe(GameEventTriggerEnter, game.onTriggerEnterCheckpoint)
+	game.Subscribe(GameEventTriggerEnter, game.onTriggerEnterMessage)
+	game.Subscribe(GameEventDeath, game.onDeath)
+	game.Subscribe(GameEventTouch, game.onTouchEnemy)
+	game.Subscribe(GameEventTouch, game.onTouchDeadly)
+	game.Subscribe(GameEventTouch, game.onTouchGoal)
 
 	return game, nil
 }
 
-// Update updates the game models
+// Update advances the game by dt (ms) - the simulation runs in fixed steps, any left over time carries over to the next Update
 func (game *Game) Update(dt float32, inputs map[GameInput]bool) {
-	if game.IsGameOver {
+	if game.hasEnded() {
 		return
 	}
 
-	if inputs[GameInputEditModeToggle] {
-		game.IsEditModeEnabled = !game.IsEditModeEnabled
+	// one-shot inputs are held until the next simulation step so they're never dropped (or applied twice)
+	for _, input := range oneShotInputs {
+		if inputs[input] {
+			game.pendingInputs[input] = true
+		}
+	}
+
+	game.accumulator += dt
+	for steps := 0; game.accumulator >= fixedTimeStep; steps++ {
+		if steps == maxStepsPerUpdate {
+			game.accumulator = 0
+			break
+		}
+
+		stepInputs := game.nextStepInputs(inputs)
+		if game.recording != nil {
+			game.recording.record(stepInputs)
+		}
+
+		game.Log = ""
+		game.step(fixedTimeStep, stepInputs)
+		game.stepLog = game.Log
+		game.accumulator -= fixedTimeStep
+
+		if game.hasEnded() {
+			break
+		}
 	}
 
+	game.interpolation = game.accumulator / fixedTimeStep
+	game.camera.follow(game.player.interpolatedPos(game.interpolation))
+
 	if game.IsEditModeEnabled {
 		player := game.player
 		camera := game.camera.(*arcballCamera)
@@ -171,39 +306,83 @@ func (game *Game) Update(dt float32, inputs map[GameInput]bool) {
 			player.pos.Z(),
 			len(game.worldBlocks),
 			len(game.enemies),
-		)
+		) + game.stepLog
 	} else {
 		game.Log = ""
 	}
+}
+
+// inputs for the next simulation step - either the live inputs or the next inputs from the replay being played back
+func (game *Game) nextStepInputs(inputs map[GameInput]bool) map[GameInput]bool {
+	if game.playback != nil {
+		game.pendingInputs = make(map[GameInput]bool) // live inputs are ignored while replaying
+
+		stepInputs := game.playback.next()
+		if game.playback.isDone() {
+			game.playback = nil
+		}
+
+		return stepInputs
+	}
+
+	stepInputs := make(map[GameInput]bool, len(inputs))
+	for input, isActive := range inputs {
+		stepInputs[input] = isActive
+	}
+	for _, input := range oneShotInputs {
+		stepInputs[input] = game.pendingInputs[input]
+		delete(game.pendingInputs, input)
+	}
 
-	game.player.update(game, dt, inputs)
+	return stepInputs
+}
+
+// step runs a single fixed length step of the simulation
+func (game *Game) step(dt float32, inputs map[GameInput]bool) {
+	if inputs[GameInputEditModeToggle] {
+		game.IsEditModeEnabled = !game.IsEditModeEnabled
+	}
+
+	// where everything is before this step (moving blocks can carry the player & enemies before they update)
+	game.player.prevPos = game.player.pos
+	for _, enemy := range game.enemies {
+		enemy.prevPos = enemy.pos
+	}
+
+	game.updateMovingBlocks(dt)
+	game.updateTimedBlocks(dt)
+
+	game.player.update(game, dt, game.editor.playerInputs(game, inputs))
 	game.camera.update(game, dt, inputs)
 
 	for _, enemy := range game.enemies {
 		enemy.update(game, dt, inputs)
 	}
 
-	for _, worldBlock := range game.worldBlocks {
-		worldBlock.update(game, dt, inputs)
-	}
+	game.updateTriggers()
 
-	if !game.IsEditModeEnabled {
-		if game.player.pos.Y() < -10.0 {
-			game.IsGameOver = true
-			return
-		}
+	if game.IsEditModeEnabled {
+		// editing starts the level over
+		game.resetLives()
+		game.resetStats()
+	} else {
+		game.updateStats(dt)
 
-		for _, enemy := range game.enemies {
-			if checkForStaticOnStaticCollision(game.player, enemy) {
-				game.IsGameOver = true
-				return
-			}
+		// deaths & goals are handled by the touch event subscribers
+		game.updateTouches()
+		if game.hasEnded() {
+			return
 		}
 	}
 
 	game.editor.update(game, dt, inputs)
 }
 
+// whether the run is over - the game stops updating once it has ended
+func (game *Game) hasEnded() bool {
+	return game.IsGameOver || game.IsLevelComplete
+}
+
 // Render renders the frame
 func (game *Game) Render() {
 	color := mgl32.Vec3{0.0, 0.0, 0.0}
@@ -236,6 +415,26 @@ func (game *Game) Render() {
 		}
 	}
 
+	// Render checkpoints
+	for i, checkpoint := range game.checkpoints {
+		if err := checkpoint.render(game, viewMatrix, i == game.checkpointIndex); err != nil {
+			panic(err)
+		}
+	}
+
+	// Render trigger volumes (only visible while editing)
+	if game.IsEditModeEnabled {
+		for _, trigger := range game.triggers {
+			if trigger.index < 0 {
+				continue
+			}
+
+			if err := trigger.render(game, viewMatrix); err != nil {
+				panic(err)
+			}
+		}
+	}
+
 	// Render editor related items
 	if err := game.editor.render(game, viewMatrix); err != nil {
 		panic(err)
@@ -253,8 +452,15 @@ func (game *Game) OnViewPortChange() {
 	game.projMatrix = mgl32.Perspective(mgl32.DegToRad(45.0), aspectRatio, 1, 50.0)
 }
 
+// MovePlayerToPos moves the player so it's right bottom back corner is at the given position
 func (game *Game) MovePlayerToPos(pos [3]float32) {
 	game.player.pos = mgl32.Vec3(pos).Add(game.player.scale)
+	game.player.prevPos = game.player.pos
+}
+
+// PlayerPos the position of the player's right bottom back corner (see MovePlayerToPos)
+func (game *Game) PlayerPos() [3]float32 {
+	return getBlockPosition(game.player)
 }
 
 var blockVerticies = [...]float32{
@@ -354,6 +560,53 @@ var blockIndicies = [...]uint16{
 	20, 22, 23,
 }
 
+// a single line segment from the origin along the x axis (see segmentModelMatrix)
+var lineVerticies = [...]float32{
+	0.0, 0.0, 0.0,
+	1.0, 0.0, 0.0,
+}
+
+var lineNormals = [...]float32{
+	0.0, 1.0, 0.0,
+	0.0, 1.0, 0.0,
+}
+
+var lineIndicies = [...]uint16{
+	0, 1,
+}
+
+// the edges of a block (see blockVerticies)
+var boxLineVerticies = [...]float32{
+	-1.0, -1.0, -1.0,
+	1.0, -1.0, -1.0,
+	1.0, 1.0, -1.0,
+	-1.0, 1.0, -1.0,
+	-1.0, -1.0, 1.0,
+	1.0, -1.0, 1.0,
+	1.0, 1.0, 1.0,
+	-1.0, 1.0, 1.0,
+}
+
+var boxLineNormals = [...]float32{
+	0.0, 1.0, 0.0,
+	0.0, 1.0, 0.0,
+	0.0, 1.0, 0.0,
+	0.0, 1.0, 0.0,
+	0.0, 1.0, 0.0,
+	0.0, 1.0, 0.0,
+	0.0, 1.0, 0.0,
+	0.0, 1.0, 0.0,
+}
+
+var boxLineIndicies = [...]uint16{
+	// back
+	0, 1, 1, 2, 2, 3, 3, 0,
+	// front
+	4, 5, 5, 6, 6, 7, 7, 4,
+	// sides
+	0, 4, 1, 5, 2, 6, 3, 7,
+}
+
 var phongVertShaderCode = `
 	precision highp float;
 
@@ -8,11 +8,52 @@ const editorActionDebounce = 1000
 type gameEditor struct {
 	timeSinceLastAction float32
 	startPos            mgl32.Vec3
-	worldBlock          *worldBlock // world block currently being created in edit mode
-	enemy               *enemy      // enemy block currently being created in edit mode
+	worldBlock          *worldBlock       // world block currently being created in edit mode
+	enemy               *enemy            // enemy block currently being created in edit mode
+	pathEnemy           *enemy            // enemy who's patrol path is currently being placed in edit mode
+	pathEnemyBefore     enemyEditState    // the pathEnemy as it was before it's path started being placed
+	material            string            // name of the material given to new world blocks (empty for their kind's material)
+	blockKind           blockKind         // kind given to new world blocks (empty for solid)
+	motionPreset        int               // index of the editorMotionPresets motion given to new world blocks
+	history             editorHistory     // changes made to the level that can be undone
+	selection           []editorTarget    // world blocks & enemies the movement keys move
+	face                editorFace        // which face of the selection the movement keys move (editorFaceNone to move all of it)
+	transform           *transformCommand // the last move/resize of the selection (so consecutive nudges undo together)
+	timeSinceNudge      float32           // time (ms) since the selection was last nudged
+	gridSize            float32           // size of the grid new & moved blocks snap to (0 for no grid)
+	clipboard           *editorClipboard  // what was last copied (nil if nothing has been)
+	highlighted         []*worldBlock
+}
+
+var pathMarkerScale = mgl32.Vec3{0.1, 0.1, 0.1}
+
+// editorMotionPreset a motion the editor can give new world blocks (other motions can be written in the level data)
+type editorMotionPreset struct {
+	name   string
+	motion *motionData // nil for static blocks
+}
+
+var editorMotionPresets = []editorMotionPreset{
+	{name: "none"},
+	{name: "oscillate x", motion: &motionData{Type: string(blockMotionOscillate), Period: 4, Amplitude: [3]float32{3, 0, 0}}},
+	{name: "oscillate y", motion: &motionData{Type: string(blockMotionOscillate), Period: 4, Amplitude: [3]float32{0, 3, 0}}},
+	{name: "oscillate z", motion: &motionData{Type: string(blockMotionOscillate), Period: 4, Amplitude: [3]float32{0, 0, 3}}},
+	{name: "orbit", motion: &motionData{Type: string(blockMotionOrbit), Period: 6, Radius: 3}},
+}
+
+// copy of the preset's motion (so blocks don't share motion data)
+func (preset editorMotionPreset) newMotionData() *motionData {
+	if preset.motion == nil {
+		return nil
+	}
+
+	motion := *preset.motion
+	return &motion
 }
 
 func (editor *gameEditor) update(game *Game, dt float32, inputs map[GameInput]bool) {
+	editor.highlightWorldBlocks(game)
+
 	if !game.IsEditModeEnabled {
 		return
 	}
@@ -25,6 +66,82 @@ func (editor *gameEditor) update(game *Game, dt float32, inputs map[GameInput]bo
 		editor.updateEnemy(game)
 	}
 
+	newBlockKind := orSolidBlockKind(editor.blockKind)
+	newBlockMaterial := editor.material
+	if newBlockMaterial == "" {
+		newBlockMaterial = blockKinds[newBlockKind].material
+	}
+	game.Log += fmt.Sprintf(
+		"<br/>New Blocks: (kind: %s\tmaterial: %s\tmotion: %s)",
+		newBlockKind,
+		newBlockMaterial,
+		editorMotionPresets[editor.motionPreset].name,
+	)
+
+	game.Log += fmt.Sprintf("<br/>Snap Grid: %s", formatGridSize(editor.gridSize))
+
+	if editor.pathEnemy != nil {
+		game.Log += fmt.Sprintf("<br/>Path (%s): %d waypoints", editor.pathEnemy.pathMode, len(editor.pathEnemy.waypoints))
+	}
+
+	// undo & redo are one-shot inputs (see oneShotInputs) so don't need debouncing
+	if inputs[GameInputEditModeUndo] {
+		editor.cancelEdits()
+		editor.history.undo(game)
+	}
+
+	if inputs[GameInputEditModeRedo] {
+		editor.cancelEdits()
+		editor.history.redo(game)
+	}
+
+	editor.validateSelection(game)
+
+	if inputs[GameInputEditModeSelect] {
+		editor.cycleSelection(game)
+	}
+
+	if inputs[GameInputEditModeSelectMore] {
+		editor.selectMore(game)
+	}
+
+	if inputs[GameInputEditModeCycleFace] {
+		editor.cycleFace()
+	}
+
+	if inputs[GameInputEditModeCycleGridSize] {
+		editor.cycleGridSize()
+	}
+
+	if inputs[GameInputEditModeCopy] {
+		editor.copySelection(game)
+	}
+
+	if inputs[GameInputEditModePaste] {
+		editor.paste(game)
+	}
+
+	if inputs[GameInputEditModeDuplicate] {
+		editor.duplicateSelection(game)
+	}
+
+	if len(editor.selection) == 1 {
+		bounds := editor.selection[0].editBounds()
+		game.Log += fmt.Sprintf(
+			"<br/>Selected %s: (x: %.2f\ty: %.2f\tz: %.2f\tw: %.2f\th: %.2f\tl: %.2f\tface: %s)",
+			editor.selection[0].describe(),
+			bounds.Position[0], bounds.Position[1], bounds.Position[2],
+			bounds.Dimensions[0], bounds.Dimensions[1], bounds.Dimensions[2],
+			editorFaceNames[editor.face],
+		)
+	} else if len(editor.selection) > 1 {
+		game.Log += fmt.Sprintf("<br/>Selected %s", describeTargets(editor.selection))
+	}
+
+	if len(editor.selection) > 0 {
+		editor.nudgeSelection(game, dt, inputs)
+	}
+
 	editor.timeSinceLastAction = editor.timeSinceLastAction + dt
 	if editor.timeSinceLastAction < editorActionDebounce {
 		return
@@ -56,6 +173,59 @@ func (editor *gameEditor) update(game *Game, dt float32, inputs map[GameInput]bo
 
 		editor.timeSinceLastAction = 0
 	}
+
+	if inputs[GameInputEditModeAddWaypoint] {
+		// on first input of add waypoint we pick the enemy to place a path for - after that each input adds a waypoint
+		if editor.pathEnemy == nil {
+			editor.createPathStart(game)
+		} else {
+			editor.addWaypoint(game)
+		}
+
+		editor.timeSinceLastAction = 0
+	}
+
+	if inputs[GameInputEditModeEndPath] {
+		editor.createPathEnd(game)
+
+		editor.timeSinceLastAction = 0
+	}
+
+	if inputs[GameInputEditModeTogglePathMode] {
+		editor.togglePathMode(game)
+
+		editor.timeSinceLastAction = 0
+	}
+
+	if inputs[GameInputEditModeCycleMaterial] {
+		editor.cycleMaterial(game)
+
+		editor.timeSinceLastAction = 0
+	}
+
+	if inputs[GameInputEditModeCycleBlockKind] {
+		editor.cycleBlockKind(game)
+
+		editor.timeSinceLastAction = 0
+	}
+
+	if inputs[GameInputEditModeCycleMotion] {
+		editor.cycleMotion(game)
+
+		editor.timeSinceLastAction = 0
+	}
+
+}
+
+// stops placing anything that's part way through being placed (a path being placed is put back as it was)
+func (editor *gameEditor) cancelEdits() {
+	editor.worldBlock = nil
+	editor.enemy = nil
+
+	if editor.pathEnemy != nil {
+		editor.pathEnemyBefore.apply(editor.pathEnemy)
+		editor.pathEnemy = nil
+	}
 }
 
 func (editor *gameEditor) render(game *Game, viewMatrix mgl32.Mat4) error {
@@ -71,33 +241,142 @@ func (editor *gameEditor) render(game *Game, viewMatrix mgl32.Mat4) error {
 		}
 	}
 
+	if !game.IsEditModeEnabled {
+		return nil
+	}
+
+	for _, enemy := range game.enemies {
+		if err := editor.renderPath(game, viewMatrix, enemy); err != nil {
+			return err
+		}
+	}
+
+	if err := editor.renderSelection(game, viewMatrix); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// renders an enemy's waypoints (& the lines between them)
+func (editor *gameEditor) renderPath(game *Game, viewMatrix mgl32.Mat4, enemy *enemy) error {
+	color := enemy.baseColor
+	if enemy == editor.pathEnemy {
+		color = enemyColorHighlighted
+	}
+
+	game.lightPos = game.player.interpolatedPos(game.interpolation)
+
+	for i, waypoint := range enemy.waypoints {
+		scaleMatrix := mgl32.Scale3D(pathMarkerScale.X(), pathMarkerScale.Y(), pathMarkerScale.Z())
+		translateMatrix := mgl32.Translate3D(waypoint.X(), waypoint.Y(), waypoint.Z())
+
+		game.modelViewMatrix = viewMatrix.Mul4(translateMatrix).Mul4(scaleMatrix)
+		game.normalMatrix = game.modelViewMatrix.Inv().Transpose()
+		game.color = color
+		game.material = mgl32.Vec4{0.4, 0.7, 1.0, 50.0}
+
+		if err := game.gl.RenderTriangles(game.blockMesh, game.phongShader); err != nil {
+			return err
+		}
+
+		// loops close back to the first waypoint - ping-pong paths just turn around
+		next := i + 1
+		if next == len(enemy.waypoints) {
+			if enemy.pathMode == enemyPathModePingPong || len(enemy.waypoints) < 3 {
+				continue
+			}
+			next = 0
+		}
+
+		modelMatrix, isVisible := segmentModelMatrix(waypoint, enemy.waypoints[next])
+		if !isVisible {
+			continue
+		}
+
+		// lines are drawn flat (ambient only) so they read the same from every angle
+		game.modelViewMatrix = viewMatrix.Mul4(modelMatrix)
+		game.normalMatrix = game.modelViewMatrix.Inv().Transpose()
+		game.color = color
+		game.material = mgl32.Vec4{1.0, 0.0, 0.0, 1.0}
+
+		if err := game.gl.RenderLines(game.lineMesh, game.phongShader); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// model matrix that maps the line mesh (a unit segment along the x axis) onto the segment between 2 positions. returns false if
+// the positions are the same
+func segmentModelMatrix(from, to mgl32.Vec3) (mgl32.Mat4, bool) {
+	dir := to.Sub(from)
+	if dir.Len() < collisionEpsilon {
+		return mgl32.Ident4(), false
+	}
+
+	// any 2 axes perpendicular to the segment will do - the line mesh has no width or height
+	up := mgl32.Vec3{0.0, 1.0, 0.0}
+	if f32Abs(dir.Normalize().Y()) > 0.99 {
+		up = mgl32.Vec3{1.0, 0.0, 0.0}
+	}
+	side := dir.Cross(up).Normalize()
+	up = side.Cross(dir).Normalize()
+
+	return mgl32.Mat4FromCols(dir.Vec4(0), up.Vec4(0), side.Vec4(0), from.Vec4(1)), true
+}
+
+// highlights the world blocks colliding with the player
+func (editor *gameEditor) highlightWorldBlocks(game *Game) {
+	for _, worldBlock := range editor.highlighted {
+		worldBlock.color = worldBlock.baseColor
+	}
+	editor.highlighted = editor.highlighted[:0]
+
+	if !game.IsEditModeEnabled {
+		return
+	}
+
+	for _, worldBlock := range game.worldIndex.query(game.player) {
+		if !checkForStaticOnStaticCollision(game.player, worldBlock) {
+			continue
+		}
+
+		worldBlock.color = worldBlockColorHighlighted
+		editor.highlighted = append(editor.highlighted, worldBlock)
+		game.Log += fmt.Sprintf("<br/>World (%s, %s): (x: %.2f\ty: %.2f\tz: %.2f)", worldBlock.kind, worldBlock.materialName(), worldBlock.pos.X(), worldBlock.pos.Y(), worldBlock.pos.Z())
+	}
+}
+
 func (editor *gameEditor) updateWorldBlock(game *Game) {
-	// we want the new right top front corner of the world block to be at the players left bottom back corner
+	// we want the new right top front corner of the world block to be at the players left bottom back corner (both corners on
+	// the snap grid)
+	startPos := snapVec3ToGrid(editor.startPos, editor.gridSize)
+	playerCorner := snapVec3ToGrid(getBlockPosition(game.player), editor.gridSize)
 	leftTopFront := mgl32.Vec3{
-		f32Max(editor.startPos.X(), game.player.right()),
-		f32Max(editor.startPos.Y(), game.player.bottom()),
-		f32Max(editor.startPos.Z(), game.player.back()),
+		f32Max(startPos.X(), playerCorner.X()),
+		f32Max(startPos.Y(), playerCorner.Y()),
+		f32Max(startPos.Z(), playerCorner.Z()),
 	}
 	rightBottomBack := mgl32.Vec3{
-		f32Min(editor.startPos.X(), game.player.right()),
-		f32Min(editor.startPos.Y(), game.player.bottom()),
-		f32Min(editor.startPos.Z(), game.player.back()),
+		f32Min(startPos.X(), playerCorner.X()),
+		f32Min(startPos.Y(), playerCorner.Y()),
+		f32Min(startPos.Z(), playerCorner.Z()),
 	}
 	widthHeightLength := leftTopFront.Add(rightBottomBack.Mul(-1.0))
 
 	editor.worldBlock.scale = widthHeightLength.Mul(0.5)
-	editor.worldBlock.pos = rightBottomBack.Add(editor.worldBlock.scale)
+	editor.worldBlock.place(rightBottomBack.Add(editor.worldBlock.scale))
 }
 
 func (editor *gameEditor) updateEnemy(game *Game) {
 	enemy := editor.enemy
 	player := game.player
 
-	// enemy should always just be directly behind the player
+	// enemy should always just be directly behind the player (with it's corners on the snap grid)
 	enemy.start = player.pos.Add(mgl32.Vec3{0.0, 0.0, -2.0 * enemy.scale.Z()})
+	enemy.start = snapPosToGrid(enemy.start, enemy.scale, editor.gridSize)
 	enemy.pos = enemy.start
 }
 
@@ -108,17 +387,34 @@ func (editor *gameEditor) createWorldBlockStart(game *Game) {
 
 	// create the currently editing world block at the players current postions
 	editor.worldBlock = new(worldBlock)
-	editor.worldBlock.pos = game.player.pos
+	editor.worldBlock.place(game.player.pos)
 	editor.worldBlock.scale = mgl32.Vec3{0.0, 0.0, 0.0} // scale changes as we move the player
-	editor.worldBlock.color = worldBlockColorDefault
+	editor.worldBlock.data.Material = editor.material
+	editor.worldBlock.data.Kind = string(editor.blockKind)
+	editor.worldBlock.data.Motion = editorMotionPresets[editor.motionPreset].newMotionData()
+	editor.worldBlock.applyData(game)
 	editor.startPos = game.player.pos
 }
 
 func (editor *gameEditor) createWorldBlockEnd(game *Game) {
 	fmt.Printf("create world block end (timeSinceLastAction: %.5f)\n", editor.timeSinceLastAction)
 
-	game.worldBlocks = append(game.worldBlocks, editor.worldBlock)
+	worldBlock := editor.worldBlock
 	editor.worldBlock = nil
+
+	// a flat block can't be collided with (& isn't a valid block in the level data)
+	if worldBlock.scale.X() <= 0 || worldBlock.scale.Y() <= 0 || worldBlock.scale.Z() <= 0 {
+		fmt.Printf("world block has no volume - discarding it\n")
+		return
+	}
+
+	worldBlock.data.blockData = newBlockData(worldBlock)
+	if game.overlapsSpawn(worldBlock.data.blockData) {
+		fmt.Printf("world block overlaps the player's spawn - discarding it\n")
+		return
+	}
+
+	editor.history.execute(game, &addWorldBlockCommand{worldBlock: worldBlock})
 }
 
 func (editor *gameEditor) createEnemyStart(game *Game) {
@@ -128,7 +424,8 @@ func (editor *gameEditor) createEnemyStart(game *Game) {
 
 	editor.enemy = new(enemy)
 	editor.enemy.scale = game.player.scale
-	editor.enemy.color = enemyColorDefault
+	editor.enemy.setKind(enemyKindChaser)
+	editor.enemy.pathMode = enemyPathModeLoop
 	editor.updateEnemy(game)
 
 	editor.startPos = game.player.pos
@@ -137,34 +434,205 @@ func (editor *gameEditor) createEnemyStart(game *Game) {
 func (editor *gameEditor) createEnemyEnd(game *Game) {
 	fmt.Printf("create enemy end (timeSinceLastAction: %.5f)\n", editor.timeSinceLastAction)
 
-	game.enemies = append(game.enemies, editor.enemy)
+	enemy := editor.enemy
 	editor.enemy = nil
+
+	enemy.data = newBlockData(enemy)
+	if game.overlapsSpawn(enemy.data) {
+		fmt.Printf("enemy overlaps the player's spawn - discarding it\n")
+		return
+	}
+
+	editor.history.execute(game, &addEnemyCommand{enemy: enemy})
+}
+
+// whether a block placed at the bounds would overlap where the player spawns (the level wouldn't import)
+func (game *Game) overlapsSpawn(bounds blockData) bool {
+	return checkForStaticOnStaticCollision(boundsOfData(game.spawn), boundsOfData(bounds))
 }
 
 func (editor *gameEditor) deleteBlocks(game *Game) {
 	fmt.Printf("deleting blocks (timeSinceLastAction: %.5f)\n", editor.timeSinceLastAction)
 
 	player := game.player
-	worldBlocks := game.worldBlocks
-	enemies := game.enemies
 
-	worldBlocksNewLen := 0
-	for i := 0; i < len(worldBlocks); i++ {
-		if !checkForStaticOnStaticCollision(player, worldBlocks[i]) {
-			worldBlocks[worldBlocksNewLen] = worldBlocks[i]
-			worldBlocksNewLen++
+	var worldBlocks []*worldBlock
+	for _, worldBlock := range game.worldIndex.query(player) {
+		if checkForStaticOnStaticCollision(player, worldBlock) {
+			worldBlocks = append(worldBlocks, worldBlock)
 		}
 	}
-	fmt.Printf("len(worldBlocks): %d\nnew len(worldBlocks): %d\n", len(worldBlocks), worldBlocksNewLen)
-	game.worldBlocks = worldBlocks[:worldBlocksNewLen]
 
-	enemiesNewLen := 0
-	for i := 0; i < len(enemies); i++ {
-		if !checkForStaticOnStaticCollision(player, enemies[i]) {
-			enemies[enemiesNewLen] = enemies[i]
-			enemiesNewLen++
+	var enemies []*enemy
+	for _, enemy := range game.enemies {
+		if checkForStaticOnStaticCollision(player, enemy) {
+			enemies = append(enemies, enemy)
 		}
 	}
-	fmt.Printf("len(enemies): %d\nnew len(enemies): %d\n", len(enemies), enemiesNewLen)
-	game.enemies = enemies[:enemiesNewLen]
+
+	if len(worldBlocks) == 0 && len(enemies) == 0 {
+		return
+	}
+
+	// a path can't be placed for a deleted enemy (it's put back as it was so undoing the delete restores it)
+	if editor.pathEnemy != nil && checkForStaticOnStaticCollision(player, editor.pathEnemy) {
+		editor.pathEnemyBefore.apply(editor.pathEnemy)
+		editor.pathEnemy = nil
+	}
+
+	editor.history.execute(game, newDeleteCommand(game, worldBlocks, enemies))
+	fmt.Printf("new len(worldBlocks): %d\nnew len(enemies): %d\n", len(game.worldBlocks), len(game.enemies))
+}
+
+// the first enemy colliding with the player (nil if there is none)
+func (editor *gameEditor) enemyAtPlayer(game *Game) *enemy {
+	for _, enemy := range game.enemies {
+		if checkForStaticOnStaticCollision(game.player, enemy) {
+			return enemy
+		}
+	}
+
+	return nil
+}
+
+func (editor *gameEditor) createPathStart(game *Game) {
+	fmt.Printf("create path start (timeSinceLastAction: %.5f)\n", editor.timeSinceLastAction)
+
+	editor.pathEnemy = editor.enemyAtPlayer(game)
+	if editor.pathEnemy == nil {
+		fmt.Printf("no enemy to create a path for\n")
+		return
+	}
+
+	// the path replaces any existing one & always starts from where the enemy starts
+	editor.pathEnemyBefore = newEnemyEditState(editor.pathEnemy)
+	editor.pathEnemy.waypoints = []mgl32.Vec3{editor.pathEnemy.start}
+	editor.pathEnemy.waypointIndex = 0
+}
+
+func (editor *gameEditor) addWaypoint(game *Game) {
+	fmt.Printf("add waypoint (timeSinceLastAction: %.5f)\n", editor.timeSinceLastAction)
+
+	editor.pathEnemy.waypoints = append(editor.pathEnemy.waypoints, game.player.pos)
+}
+
+func (editor *gameEditor) createPathEnd(game *Game) {
+	fmt.Printf("create path end (timeSinceLastAction: %.5f)\n", editor.timeSinceLastAction)
+
+	enemy := editor.pathEnemy
+	if enemy == nil {
+		return
+	}
+	editor.pathEnemy = nil
+
+	// a path needs somewhere to go other than the start - the enemy keeps the path it had
+	if len(enemy.waypoints) < 2 {
+		fmt.Printf("path has no waypoints - keeping the old path\n")
+		editor.pathEnemyBefore.apply(enemy)
+		return
+	}
+
+	// enemies with a path walk it instead of chasing
+	if enemy.kind != enemyKindPatroller {
+		enemy.setKind(enemyKindPatroller)
+	}
+
+	editor.history.execute(game, &editEnemyCommand{
+		description: fmt.Sprintf("set path (%d waypoints)", len(enemy.waypoints)),
+		enemy:       enemy,
+		before:      editor.pathEnemyBefore,
+		after:       newEnemyEditState(enemy),
+	})
+}
+
+func (editor *gameEditor) togglePathMode(game *Game) {
+	fmt.Printf("toggle path mode (timeSinceLastAction: %.5f)\n", editor.timeSinceLastAction)
+
+	// the path being placed is undone as a whole (including it's mode)
+	if editor.pathEnemy != nil {
+		editor.pathEnemy.pathMode = nextEnemyPathMode(editor.pathEnemy.pathMode)
+		return
+	}
+
+	enemy := editor.enemyAtPlayer(game)
+	if enemy == nil {
+		return
+	}
+
+	before := newEnemyEditState(enemy)
+	after := newEnemyEditState(enemy)
+	after.pathMode = nextEnemyPathMode(enemy.pathMode)
+
+	editor.history.execute(game, &editEnemyCommand{
+		description: fmt.Sprintf("set path mode (%s)", after.pathMode),
+		enemy:       enemy,
+		before:      before,
+		after:       after,
+	})
+}
+
+// new world blocks go back to using their kind's material if the level doesn't have the editor's material (ex. it was from
+// another level)
+func (editor *gameEditor) forgetMissingMaterial(game *Game) {
+	if _, isFound := findMaterial(game.materials, editor.material); !isFound {
+		editor.material = ""
+	}
+}
+
+// switches new world blocks to the next material available to the level (after the last one new blocks go back to using their
+// kind's material)
+func (editor *gameEditor) cycleMaterial(game *Game) {
+	fmt.Printf("cycle material (timeSinceLastAction: %.5f)\n", editor.timeSinceLastAction)
+
+	names := game.materialNames()
+	next := ""
+	if editor.material == "" {
+		next = names[0]
+	}
+	for i, name := range names {
+		if name == editor.material && i+1 < len(names) {
+			next = names[i+1]
+		}
+	}
+
+	editor.material = next
+
+	if editor.worldBlock != nil {
+		editor.worldBlock.data.Material = editor.material
+		editor.worldBlock.applyData(game)
+	}
+}
+
+// switches new world blocks to the next block kind
+func (editor *gameEditor) cycleBlockKind(game *Game) {
+	fmt.Printf("cycle block kind (timeSinceLastAction: %.5f)\n", editor.timeSinceLastAction)
+
+	next := 0
+	for i, kind := range blockKindNames {
+		if kind == orSolidBlockKind(editor.blockKind) {
+			next = (i + 1) % len(blockKindNames)
+		}
+	}
+
+	editor.blockKind = blockKindNames[next]
+	if editor.blockKind == blockKindSolid {
+		editor.blockKind = "" // solid isn't written out to the level data
+	}
+
+	if editor.worldBlock != nil {
+		editor.worldBlock.data.Kind = string(editor.blockKind)
+		editor.worldBlock.applyData(game)
+	}
+}
+
+// switches new world blocks to the next motion preset
+func (editor *gameEditor) cycleMotion(game *Game) {
+	fmt.Printf("cycle motion (timeSinceLastAction: %.5f)\n", editor.timeSinceLastAction)
+
+	editor.motionPreset = (editor.motionPreset + 1) % len(editorMotionPresets)
+
+	if editor.worldBlock != nil {
+		editor.worldBlock.data.Motion = editorMotionPresets[editor.motionPreset].newMotionData()
+		editor.worldBlock.applyData(game)
+	}
 }
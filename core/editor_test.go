@@ -0,0 +1,151 @@
+package core_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cpoonolly/blockgame/core"
+	"github.com/cpoonolly/blockgame/headless"
+)
+
+// a block under the player's spawn & a patroller off to the side
+const editorLevel = `{
+	"player": {"position": [-0.5, 3, -0.5], "dimensions": [1, 1, 1]},
+	"world": [{"position": [-2, 0, -2], "dimensions": [4, 3, 4]}],
+	"enemies": [{"position": [5, 3, 5], "dimensions": [1, 1, 1], "type": "patroller", "waypoints": [[5.5, 3.5, 5.5], [8.5, 3.5, 5.5]]}]
+}`
+
+// a game with the level loaded in edit mode
+func newEditorGame(t *testing.T, level string) *core.Game {
+	game, err := core.NewGame(headless.NewRecorder(320, 240))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := game.ImportFromJSON(level); err != nil {
+		t.Fatal(err)
+	}
+
+	press(game, core.GameInputEditModeToggle)
+	if !game.IsEditModeEnabled {
+		t.Fatal("expected edit mode")
+	}
+
+	return game
+}
+
+// holds the input down for a step then lets the editor settle (longer than it takes to accept another action)
+func press(game *core.Game, input core.GameInput) {
+	game.Update(1000.0/60.0, map[core.GameInput]bool{input: true})
+	for i := 0; i < 80; i++ {
+		game.Update(1000.0/60.0, nil)
+	}
+}
+
+type exportedLevel struct {
+	World   []json.RawMessage `json:"world"`
+	Enemies []struct {
+		Type      string       `json:"type"`
+		Waypoints [][3]float32 `json:"waypoints"`
+	} `json:"enemies"`
+}
+
+// exports the level & checks it imports again (returns what was exported)
+func assertReimports(t *testing.T, game *core.Game) exportedLevel {
+	levelJSON := game.ExportAsJSON()
+
+	reimported, err := core.NewGame(headless.NewRecorder(320, 240))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := reimported.ImportFromJSON(levelJSON); err != nil {
+		t.Errorf("expected the exported level to import, got %v", err)
+	}
+
+	var level exportedLevel
+	if err := json.Unmarshal([]byte(levelJSON), &level); err != nil {
+		t.Fatal(err)
+	}
+
+	return level
+}
+
+func TestEndingAnEmptyPathKeepsThePath(t *testing.T) {
+	game := newEditorGame(t, editorLevel)
+
+	game.MovePlayerToPos([3]float32{5, 3, 5})
+	press(game, core.GameInputEditModeAddWaypoint)
+	press(game, core.GameInputEditModeEndPath)
+
+	level := assertReimports(t, game)
+	if enemy := level.Enemies[0]; enemy.Type != "patroller" || len(enemy.Waypoints) != 2 {
+		t.Errorf("expected the patroller to keep it's 2 waypoints, got %+v", enemy)
+	}
+}
+
+func TestCreatedBlocksCantOverlapTheSpawn(t *testing.T) {
+	game := newEditorGame(t, editorLevel)
+
+	// the player starts in the spawn
+	press(game, core.GameInputEditModeCreateWorldBlock)
+	press(game, core.GameInputEditModeCreateWorldBlock)
+
+	// enemies are created just behind the player
+	game.MovePlayerToPos([3]float32{-0.5, 3, 0.5})
+	press(game, core.GameInputEditModeCreateEnemy)
+	press(game, core.GameInputEditModeCreateEnemy)
+
+	level := assertReimports(t, game)
+	if len(level.World) != 1 || len(level.Enemies) != 1 {
+		t.Errorf("expected nothing to be created in the spawn, got %d world blocks & %d enemies", len(level.World), len(level.Enemies))
+	}
+}
+
+func TestNudgesCantOverlapTheSpawn(t *testing.T) {
+	game := newEditorGame(t, editorLevel)
+
+	// the block under the spawn is nearest the player (a couple of nudges up would put it halfway into the spawn)
+	press(game, core.GameInputEditModeSelect)
+	press(game, core.GameInputEditModeMoveUp)
+	press(game, core.GameInputEditModeMoveUp)
+
+	assertReimports(t, game)
+}
+
+func TestPlacedBlocksCantOverlapTheSpawn(t *testing.T) {
+	game := newEditorGame(t, editorLevel)
+
+	// the camera looks at the player so the middle of the viewport is the top of the block under the spawn
+	if game.PlaceBlockAt(160, 120) {
+		t.Errorf("expected no block to be placed in the spawn")
+	}
+
+	if level := assertReimports(t, game); len(level.World) != 1 {
+		t.Errorf("expected nothing to be placed in the spawn, got %d world blocks", len(level.World))
+	}
+}
+
+func TestPastesCantOverlapTheSpawn(t *testing.T) {
+	game := newEditorGame(t, editorLevel)
+
+	press(game, core.GameInputEditModeSelect)
+	press(game, core.GameInputEditModeCopy)
+	if err := game.SavePrefab("base"); err != nil {
+		t.Fatal(err)
+	}
+
+	// pasted so the copy's in the same place relative to the player - moving the player up by the block's height puts the copy
+	// right over the spawn
+	game.MovePlayerToPos([3]float32{-0.5, 6, -0.5})
+	press(game, core.GameInputEditModePaste)
+
+	// prefabs are placed with their corner at the player's
+	game.MovePlayerToPos([3]float32{-0.5, 3, -0.5})
+	if err := game.PlacePrefab("base"); err == nil {
+		t.Errorf("expected placing the prefab in the spawn to fail")
+	}
+
+	if level := assertReimports(t, game); len(level.World) != 1 {
+		t.Errorf("expected nothing to be placed in the spawn, got %d world blocks", len(level.World))
+	}
+}
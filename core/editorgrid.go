@@ -0,0 +1,234 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// sizes the editor's snap grid can be switched between (0 for no grid)
+var editorGridSizes = []float32{0, 0.25, 0.5, 1}
+
+// rounds the value to the nearest multiple of the grid size (unchanged if there's no grid)
+func snapToGrid(value, gridSize float32) float32 {
+	if gridSize <= 0 {
+		return value
+	}
+
+	return float32(math.Round(float64(value/gridSize))) * gridSize
+}
+
+func snapVec3ToGrid(vec mgl32.Vec3, gridSize float32) mgl32.Vec3 {
+	return mgl32.Vec3{snapToGrid(vec.X(), gridSize), snapToGrid(vec.Y(), gridSize), snapToGrid(vec.Z(), gridSize)}
+}
+
+// snaps both corners of the block to the grid - a block too thin to span a grid cell is given a whole cell
+func snapBlockDataToGrid(data blockData, gridSize float32) blockData {
+	if gridSize <= 0 {
+		return data
+	}
+
+	bounds := boundsOfData(data)
+	min := snapVec3ToGrid(bounds.min, gridSize)
+	max := snapVec3ToGrid(bounds.max, gridSize)
+	for axis := 0; axis < 3; axis++ {
+		max[axis] = f32Max(max[axis], min[axis]+gridSize)
+	}
+
+	return blockData{Position: min, Dimensions: max.Sub(min)}
+}
+
+// snaps the position (center) of a block with the given scale so it's corners are on the grid
+func snapPosToGrid(pos mgl32.Vec3, scale mgl32.Vec3, gridSize float32) mgl32.Vec3 {
+	return snapVec3ToGrid(pos.Sub(scale), gridSize).Add(scale)
+}
+
+// switches the snap grid to the next size (after the largest there's no grid)
+func (editor *gameEditor) cycleGridSize() {
+	next := 0
+	for i, gridSize := range editorGridSizes {
+		if gridSize == editor.gridSize {
+			next = (i + 1) % len(editorGridSizes)
+		}
+	}
+
+	editor.gridSize = editorGridSizes[next]
+	fmt.Printf("snap grid: %s\n", formatGridSize(editor.gridSize))
+}
+
+func formatGridSize(gridSize float32) string {
+	if gridSize <= 0 {
+		return "off"
+	}
+
+	return fmt.Sprintf("%.2f", gridSize)
+}
+
+// EditorGridSize the size of the grid the editor snaps new & moved blocks to (0 if snapping is off)
+func (game *Game) EditorGridSize() float32 {
+	return game.editor.gridSize
+}
+
+// snaps the world blocks to the grid (in place)
+func snapWorldToGrid(world []worldBlockData, gridSize float32) {
+	for i := range world {
+		world[i].blockData = snapBlockDataToGrid(world[i].blockData, gridSize)
+	}
+}
+
+// snaps the enemies & their waypoints to the grid (in place)
+func snapEnemiesToGrid(enemies []enemyData, gridSize float32) {
+	for i := range enemies {
+		enemy := &enemies[i]
+		enemy.blockData = snapBlockDataToGrid(enemy.blockData, gridSize)
+
+		// waypoints are where the enemy's center goes
+		scale := getBlockScaleFromData(enemy.blockData)
+		for j := range enemy.Waypoints {
+			enemy.Waypoints[j] = snapPosToGrid(enemy.Waypoints[j], scale, gridSize)
+		}
+	}
+}
+
+// levelLayout where everything in the level other than the world blocks & enemies is (& the enemies' paths) - the parts of a
+// level quantizing changes that editing a block doesn't
+type levelLayout struct {
+	spawn     blockData
+	triggers  []blockData    // volume of each of game.triggers (including the checkpoints' volumes)
+	waypoints [][]mgl32.Vec3 // path of each of the enemies quantized
+	prefabs   []prefabData
+}
+
+func (game *Game) levelLayout(enemies []*enemy) levelLayout {
+	layout := levelLayout{spawn: game.spawn, prefabs: game.prefabs}
+
+	for _, trigger := range game.triggers {
+		layout.triggers = append(layout.triggers, trigger.data.blockData)
+	}
+
+	for _, enemy := range enemies {
+		layout.waypoints = append(layout.waypoints, append([]mgl32.Vec3(nil), enemy.waypoints...))
+	}
+
+	return layout
+}
+
+func (game *Game) setLevelLayout(enemies []*enemy, layout levelLayout) {
+	game.spawn = layout.spawn
+	game.prefabs = layout.prefabs
+
+	for i, trigger := range game.triggers {
+		trigger.data.blockData = layout.triggers[i]
+		trigger.pos = getBlockPosFromData(layout.triggers[i])
+		trigger.scale = getBlockScaleFromData(layout.triggers[i])
+
+		if trigger.checkpoint >= 0 {
+			checkpoint := game.checkpoints[trigger.checkpoint]
+			checkpoint.data = layout.triggers[i]
+			checkpoint.pos = trigger.pos
+			checkpoint.scale = trigger.scale
+		}
+	}
+
+	for i, enemy := range enemies {
+		enemy.waypoints = append([]mgl32.Vec3(nil), layout.waypoints[i]...)
+	}
+}
+
+// quantizeCommand snaps the whole level to a grid
+type quantizeCommand struct {
+	gridSize  float32
+	transform transformCommand // every world block & enemy
+	enemies   []*enemy
+	before    levelLayout
+	after     levelLayout
+}
+
+// the enemies' paths are set after they're moved (moving an enemy moves it's path along with it)
+func (command *quantizeCommand) do(game *Game) {
+	command.transform.do(game)
+	game.setLevelLayout(command.enemies, command.after)
+}
+
+func (command *quantizeCommand) undo(game *Game) {
+	command.transform.undo(game)
+	game.setLevelLayout(command.enemies, command.before)
+}
+
+func (command *quantizeCommand) describe() string {
+	return fmt.Sprintf("quantize level (grid: %s)", formatGridSize(command.gridSize))
+}
+
+// QuantizeLevel snaps every block, enemy, checkpoint & trigger in the level (& the player's spawn & the contents of the level's
+// prefabs) to a grid of the given size. the player stays where they are. it's a single change in the editor's history so it
+// can be undone - returns LevelValidationErrors if snapping would make the level unplayable (ex. the spawn now overlaps a block)
+// & leaves the game untouched
+func (game *Game) QuantizeLevel(gridSize float32) error {
+	if gridSize <= 0 {
+		return fmt.Errorf("invalid grid size: %v", gridSize)
+	}
+
+	var data gameData
+	if err := json.Unmarshal([]byte(game.ExportAsJSON()), &data); err != nil {
+		return err
+	}
+
+	data.Player = snapBlockDataToGrid(data.Player, gridSize)
+
+	for i := range data.Checkpoints {
+		data.Checkpoints[i] = snapBlockDataToGrid(data.Checkpoints[i], gridSize)
+	}
+
+	for i := range data.Triggers {
+		data.Triggers[i].blockData = snapBlockDataToGrid(data.Triggers[i].blockData, gridSize)
+	}
+
+	snapWorldToGrid(data.World, gridSize)
+	snapEnemiesToGrid(data.Enemies, gridSize)
+
+	for i := range data.Prefabs {
+		snapWorldToGrid(data.Prefabs[i].World, gridSize)
+		snapEnemiesToGrid(data.Prefabs[i].Enemies, gridSize)
+	}
+
+	if err := validateLevel(&data); err != nil {
+		return err
+	}
+
+	// the exported level lists everything in the same order as the game
+	command := &quantizeCommand{gridSize: gridSize, enemies: append([]*enemy(nil), game.enemies...)}
+	command.before = game.levelLayout(command.enemies)
+	command.after = levelLayout{spawn: data.Player, prefabs: data.Prefabs}
+
+	for i, worldBlock := range game.worldBlocks {
+		command.transform.targets = append(command.transform.targets, worldBlock)
+		command.transform.before = append(command.transform.before, worldBlock.editBounds())
+		command.transform.after = append(command.transform.after, data.World[i].blockData)
+	}
+
+	for i, enemy := range game.enemies {
+		command.transform.targets = append(command.transform.targets, enemy)
+		command.transform.before = append(command.transform.before, enemy.editBounds())
+		command.transform.after = append(command.transform.after, data.Enemies[i].blockData)
+
+		var waypoints []mgl32.Vec3
+		for _, waypoint := range data.Enemies[i].Waypoints {
+			waypoints = append(waypoints, mgl32.Vec3(waypoint))
+		}
+		command.after.waypoints = append(command.after.waypoints, waypoints)
+	}
+
+	for _, trigger := range game.triggers {
+		if trigger.checkpoint >= 0 {
+			command.after.triggers = append(command.after.triggers, data.Checkpoints[trigger.checkpoint])
+		} else {
+			command.after.triggers = append(command.after.triggers, data.Triggers[trigger.index].blockData)
+		}
+	}
+
+	game.editor.history.execute(game, command)
+
+	return nil
+}
@@ -0,0 +1,248 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// # of editor commands that can be undone when not set with SetEditorHistoryDepth
+const defaultEditorHistoryDepth = 100
+
+// editorCommand a reversible change made to the level in edit mode
+type editorCommand interface {
+	do(game *Game)
+	undo(game *Game)
+	// short description of the change (shown in the editor panel)
+	describe() string
+}
+
+// editorHistory the commands that can be undone & redone (most recent last)
+type editorHistory struct {
+	undoStack []editorCommand
+	redoStack []editorCommand
+}
+
+// does the command & makes it the most recent one that can be undone (anything undone can no longer be redone)
+func (history *editorHistory) execute(game *Game, command editorCommand) {
+	command.do(game)
+
+	history.undoStack = append(history.undoStack, command)
+	history.redoStack = nil
+	history.limit(game.editorHistoryDepth)
+
+	fmt.Printf("did: %s\n", command.describe())
+}
+
+// forgets the oldest commands so at most depth can be undone
+func (history *editorHistory) limit(depth int) {
+	if len(history.undoStack) > depth {
+		history.undoStack = append([]editorCommand(nil), history.undoStack[len(history.undoStack)-depth:]...)
+	}
+}
+
+// whether the command is the next one that would be undone
+func (history *editorHistory) isLatest(command editorCommand) bool {
+	return len(history.undoStack) > 0 && history.undoStack[len(history.undoStack)-1] == command
+}
+
+func (history *editorHistory) undo(game *Game) {
+	if len(history.undoStack) == 0 {
+		return
+	}
+
+	command := history.undoStack[len(history.undoStack)-1]
+	history.undoStack = history.undoStack[:len(history.undoStack)-1]
+	command.undo(game)
+	history.redoStack = append(history.redoStack, command)
+
+	fmt.Printf("undid: %s\n", command.describe())
+}
+
+func (history *editorHistory) redo(game *Game) {
+	if len(history.redoStack) == 0 {
+		return
+	}
+
+	command := history.redoStack[len(history.redoStack)-1]
+	history.redoStack = history.redoStack[:len(history.redoStack)-1]
+	command.do(game)
+	history.undoStack = append(history.undoStack, command)
+
+	fmt.Printf("redid: %s\n", command.describe())
+}
+
+// EditorHistory descriptions of the editor changes that can be undone & redone
+type EditorHistory struct {
+	Undo []string // oldest first (the last is undone next)
+	Redo []string // most recently undone first (the first is redone next)
+}
+
+// EditorHistory the editor changes that can currently be undone & redone
+func (game *Game) EditorHistory() EditorHistory {
+	history := game.editor.history
+
+	var descriptions EditorHistory
+	for _, command := range history.undoStack {
+		descriptions.Undo = append(descriptions.Undo, command.describe())
+	}
+	for i := len(history.redoStack) - 1; i >= 0; i-- {
+		descriptions.Redo = append(descriptions.Redo, history.redoStack[i].describe())
+	}
+
+	return descriptions
+}
+
+// SetEditorHistoryDepth sets how many editor changes can be undone (older changes are forgotten)
+func (game *Game) SetEditorHistoryDepth(depth int) {
+	if depth < 0 {
+		depth = 0
+	}
+
+	game.editorHistoryDepth = depth
+	game.editor.history.limit(depth)
+}
+
+// addWorldBlockCommand adds a new world block
+type addWorldBlockCommand struct {
+	worldBlock *worldBlock
+}
+
+func (command *addWorldBlockCommand) do(game *Game) {
+	game.addWorldBlock(command.worldBlock)
+}
+
+func (command *addWorldBlockCommand) undo(game *Game) {
+	game.removeWorldBlock(command.worldBlock)
+}
+
+func (command *addWorldBlockCommand) describe() string {
+	return fmt.Sprintf("add world block (%s)", command.worldBlock.kind)
+}
+
+// addEnemyCommand adds a new enemy
+type addEnemyCommand struct {
+	enemy *enemy
+}
+
+func (command *addEnemyCommand) do(game *Game) {
+	game.insertEnemy(command.enemy, len(game.enemies))
+}
+
+func (command *addEnemyCommand) undo(game *Game) {
+	game.removeEnemy(command.enemy)
+}
+
+func (command *addEnemyCommand) describe() string {
+	return fmt.Sprintf("add enemy (%s)", command.enemy.kind)
+}
+
+// deleteCommand deletes world blocks & enemies - undoing puts them back where they were in the level's order
+type deleteCommand struct {
+	worldBlocks       []*worldBlock
+	worldBlockIndexes []int // indexes in game.worldBlocks before the delete (ascending)
+	enemies           []*enemy
+	enemyIndexes      []int // indexes in game.enemies before the delete (ascending)
+}
+
+func newDeleteCommand(game *Game, worldBlocks []*worldBlock, enemies []*enemy) *deleteCommand {
+	command := new(deleteCommand)
+
+	for i, worldBlock := range game.worldBlocks {
+		for _, deleted := range worldBlocks {
+			if worldBlock == deleted {
+				command.worldBlocks = append(command.worldBlocks, worldBlock)
+				command.worldBlockIndexes = append(command.worldBlockIndexes, i)
+			}
+		}
+	}
+
+	for i, enemy := range game.enemies {
+		for _, deleted := range enemies {
+			if enemy == deleted {
+				command.enemies = append(command.enemies, enemy)
+				command.enemyIndexes = append(command.enemyIndexes, i)
+			}
+		}
+	}
+
+	return command
+}
+
+func (command *deleteCommand) do(game *Game) {
+	for _, worldBlock := range command.worldBlocks {
+		game.removeWorldBlock(worldBlock)
+	}
+
+	for _, enemy := range command.enemies {
+		game.removeEnemy(enemy)
+	}
+}
+
+func (command *deleteCommand) undo(game *Game) {
+	// in ascending order so each index is where it was once everything before it is back
+	for i, worldBlock := range command.worldBlocks {
+		game.insertWorldBlock(worldBlock, command.worldBlockIndexes[i])
+	}
+
+	for i, enemy := range command.enemies {
+		game.insertEnemy(enemy, command.enemyIndexes[i])
+	}
+}
+
+func (command *deleteCommand) describe() string {
+	return fmt.Sprintf("delete %d world blocks & %d enemies", len(command.worldBlocks), len(command.enemies))
+}
+
+// enemyEditState the parts of an enemy the editor changes once it's placed
+type enemyEditState struct {
+	kind            enemyKind
+	speed           float32
+	detectionRadius float32
+	baseColor       mgl32.Vec4
+	waypoints       []mgl32.Vec3
+	pathMode        enemyPathMode
+}
+
+func newEnemyEditState(enemy *enemy) enemyEditState {
+	return enemyEditState{
+		kind:            enemy.kind,
+		speed:           enemy.speed,
+		detectionRadius: enemy.detectionRadius,
+		baseColor:       enemy.baseColor,
+		waypoints:       append([]mgl32.Vec3(nil), enemy.waypoints...),
+		pathMode:        enemy.pathMode,
+	}
+}
+
+func (state enemyEditState) apply(enemy *enemy) {
+	enemy.setKind(state.kind)
+	enemy.speed = state.speed
+	enemy.detectionRadius = state.detectionRadius
+	enemy.baseColor = state.baseColor
+	enemy.color = state.baseColor
+	enemy.waypoints = append([]mgl32.Vec3(nil), state.waypoints...)
+	enemy.pathMode = state.pathMode
+	enemy.waypointIndex = 0
+	enemy.isReturning = false
+}
+
+// editEnemyCommand changes an enemy's kind, path etc.
+type editEnemyCommand struct {
+	description string
+	enemy       *enemy
+	before      enemyEditState
+	after       enemyEditState
+}
+
+func (command *editEnemyCommand) do(game *Game) {
+	command.after.apply(command.enemy)
+}
+
+func (command *editEnemyCommand) undo(game *Game) {
+	command.before.apply(command.enemy)
+}
+
+func (command *editEnemyCommand) describe() string {
+	return command.description
+}
@@ -0,0 +1,499 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// how far (from the player) the editor looks for things to select when the player isn't overlapping anything
+const editorSelectRadius float32 = 5
+
+// how far a single nudge moves the selection (or one of it's faces) when there's no snap grid
+const editorNudgeStep float32 = 0.5
+
+// how often (ms) a nudge repeats while a movement key is held
+const editorNudgeInterval float32 = 150
+
+// smallest a dimension of the selection can be resized to when there's no snap grid
+const editorMinDimension float32 = 0.1
+
+// inputs that move the selection rather than the player
+var editorNudgeInputs = []GameInput{
+	GameInputPlayerMoveForward,
+	GameInputPlayerMoveBack,
+	GameInputPlayerMoveLeft,
+	GameInputPlayerMoveRight,
+	GameInputEditModeMoveUp,
+	GameInputEditModeMoveDown,
+}
+
+var editorSelectionColor = mgl32.Vec4{0.1, 0.9, 1.0, 1.0}
+
+// editorTarget something in the level that can be selected, moved & resized in the editor
+type editorTarget interface {
+	collidable
+	// bounds of the target as authored in the level
+	editBounds() blockData
+	// moves/resizes the target to the given bounds
+	setEditBounds(game *Game, bounds blockData)
+	// short description (for the editor's log & history)
+	describe() string
+}
+
+func (worldBlock *worldBlock) editBounds() blockData {
+	return worldBlock.data.blockData
+}
+
+func (worldBlock *worldBlock) setEditBounds(game *Game, bounds blockData) {
+	worldBlock.data.blockData = bounds
+	worldBlock.scale = getBlockScaleFromData(bounds)
+	worldBlock.place(getBlockPosFromData(bounds))
+
+	game.worldIndex.update(worldBlock)
+	game.isNavGraphDirty = true
+}
+
+func (worldBlock *worldBlock) describe() string {
+	return fmt.Sprintf("world block (%s)", worldBlock.kind)
+}
+
+func (enemy *enemy) editBounds() blockData {
+	return enemy.data
+}
+
+// the enemy's path moves along with it
+func (enemy *enemy) setEditBounds(game *Game, bounds blockData) {
+	start := getBlockPosFromData(bounds)
+	offset := start.Sub(enemy.start)
+	for i := range enemy.waypoints {
+		enemy.waypoints[i] = enemy.waypoints[i].Add(offset)
+	}
+
+	enemy.data = bounds
+	enemy.scale = getBlockScaleFromData(bounds)
+	enemy.start = start
+	enemy.pos = start
+	enemy.prevPos = start
+}
+
+func (enemy *enemy) describe() string {
+	return fmt.Sprintf("enemy (%s)", enemy.kind)
+}
+
+// editorFace which part of the selection the movement keys move - the whole thing or one of it's faces
+type editorFace int
+
+const (
+	editorFaceNone editorFace = iota // moves the whole selection
+	editorFaceLeft
+	editorFaceRight
+	editorFaceTop
+	editorFaceBottom
+	editorFaceFront
+	editorFaceBack
+	editorFaceCount
+)
+
+var editorFaceNames = map[editorFace]string{
+	editorFaceNone:   "none (move)",
+	editorFaceLeft:   "left",
+	editorFaceRight:  "right",
+	editorFaceTop:    "top",
+	editorFaceBottom: "bottom",
+	editorFaceFront:  "front",
+	editorFaceBack:   "back",
+}
+
+// the axis the face is on & whether it's on the max side of the axis (see collidable for which way is which)
+func (face editorFace) axis() (int, bool) {
+	switch face {
+	case editorFaceLeft:
+		return 0, true
+	case editorFaceRight:
+		return 0, false
+	case editorFaceTop:
+		return 1, true
+	case editorFaceBottom:
+		return 1, false
+	case editorFaceFront:
+		return 2, true
+	case editorFaceBack:
+		return 2, false
+	}
+
+	return -1, false
+}
+
+// transformCommand moves/resizes world blocks & enemies
+type transformCommand struct {
+	targets []editorTarget
+	before  []blockData
+	after   []blockData
+}
+
+func (command *transformCommand) do(game *Game) {
+	for i, target := range command.targets {
+		target.setEditBounds(game, command.after[i])
+	}
+}
+
+func (command *transformCommand) undo(game *Game) {
+	for i, target := range command.targets {
+		target.setEditBounds(game, command.before[i])
+	}
+}
+
+func (command *transformCommand) describe() string {
+	for i := range command.targets {
+		if command.before[i].Dimensions != command.after[i].Dimensions {
+			return fmt.Sprintf("resize %s", describeTargets(command.targets))
+		}
+	}
+
+	return fmt.Sprintf("move %s", describeTargets(command.targets))
+}
+
+// short description of a group of world blocks & enemies (for the editor's log & history)
+func describeTargets(targets []editorTarget) string {
+	if len(targets) == 1 {
+		return targets[0].describe()
+	}
+
+	var worldBlocks, enemies int
+	for _, target := range targets {
+		switch target.(type) {
+		case *worldBlock:
+			worldBlocks++
+		case *enemy:
+			enemies++
+		}
+	}
+
+	return fmt.Sprintf("%d world blocks & %d enemies", worldBlocks, enemies)
+}
+
+// whether the target is still in the level (it may have been deleted or undone)
+func (game *Game) isInLevel(target editorTarget) bool {
+	for _, worldBlock := range game.worldBlocks {
+		if target == editorTarget(worldBlock) {
+			return true
+		}
+	}
+
+	for _, enemy := range game.enemies {
+		if target == editorTarget(enemy) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// what can be selected - things overlapping the player first then anything else within editorSelectRadius (nearest first)
+func (editor *gameEditor) selectCandidates(game *Game) []editorTarget {
+	player := game.player
+	radius := mgl32.Vec3{editorSelectRadius, editorSelectRadius, editorSelectRadius}
+	searchBounds := bounds{min: boundsOf(player).min.Sub(radius), max: boundsOf(player).max.Add(radius)}
+
+	var candidates []editorTarget
+	for _, worldBlock := range game.worldIndex.query(searchBounds) {
+		candidates = append(candidates, worldBlock)
+	}
+	for _, enemy := range game.enemies {
+		if checkForStaticOnStaticCollision(searchBounds, enemy) {
+			candidates = append(candidates, enemy)
+		}
+	}
+
+	// distance from the player to the nearest point of the candidate (0 when overlapping)
+	distance := func(target editorTarget) float32 {
+		bounds := boundsOf(target)
+		var nearest mgl32.Vec3
+		for axis := 0; axis < 3; axis++ {
+			nearest[axis] = f32LimitBetween(player.pos[axis], bounds.min[axis], bounds.max[axis])
+		}
+
+		return nearest.Sub(player.pos).Len()
+	}
+
+	// sort.SliceStable keeps the level's order for ties so cycling is predictable
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return distance(candidates[i]) < distance(candidates[j])
+	})
+
+	for i, candidate := range candidates {
+		if distance(candidate) > editorSelectRadius {
+			return candidates[:i]
+		}
+	}
+
+	return candidates
+}
+
+// selects only the target (nothing if it's nil)
+func (editor *gameEditor) selectOnly(target editorTarget) {
+	editor.selection = nil
+	if target != nil {
+		editor.selection = []editorTarget{target}
+	}
+
+	editor.onSelectionChange()
+}
+
+// adds the target to the selection (or removes it if it's already selected)
+func (editor *gameEditor) toggleSelected(target editorTarget) {
+	for i, selected := range editor.selection {
+		if selected == target {
+			editor.selection = append(editor.selection[:i:i], editor.selection[i+1:]...)
+			editor.onSelectionChange()
+			return
+		}
+	}
+
+	editor.selection = append(editor.selection, target)
+	editor.onSelectionChange()
+}
+
+func (editor *gameEditor) isSelected(target editorTarget) bool {
+	for _, selected := range editor.selection {
+		if selected == target {
+			return true
+		}
+	}
+
+	return false
+}
+
+// faces can only be moved with a single thing selected - & nudges of a different selection are undone separately
+func (editor *gameEditor) onSelectionChange() {
+	if len(editor.selection) != 1 {
+		editor.face = editorFaceNone
+	}
+	editor.transform = nil
+
+	if len(editor.selection) == 0 {
+		fmt.Printf("deselected\n")
+	} else {
+		fmt.Printf("selected %s\n", describeTargets(editor.selection))
+	}
+}
+
+// forgets anything selected that's no longer in the level (it may have been deleted or it's creation undone)
+func (editor *gameEditor) validateSelection(game *Game) {
+	selection := editor.selection[:0]
+	for _, target := range editor.selection {
+		if game.isInLevel(target) {
+			selection = append(selection, target)
+		}
+	}
+
+	if len(selection) != len(editor.selection) {
+		editor.selection = selection
+		editor.onSelectionChange()
+	}
+}
+
+// selects the nearest thing to the player - or if a single thing is already selected the next nearest thing (after the last
+// nothing is selected)
+func (editor *gameEditor) cycleSelection(game *Game) {
+	candidates := editor.selectCandidates(game)
+
+	next := 0
+	for i, candidate := range candidates {
+		if len(editor.selection) == 1 && candidate == editor.selection[0] {
+			next = i + 1
+		}
+	}
+
+	editor.face = editorFaceNone
+	if next < len(candidates) {
+		editor.selectOnly(candidates[next])
+	} else {
+		editor.selectOnly(nil)
+	}
+}
+
+// adds the nearest thing to the player that isn't already selected to the selection
+func (editor *gameEditor) selectMore(game *Game) {
+	for _, candidate := range editor.selectCandidates(game) {
+		if !editor.isSelected(candidate) {
+			editor.toggleSelected(candidate)
+			return
+		}
+	}
+
+	fmt.Printf("nothing else to select\n")
+}
+
+// switches the movement keys between moving the selection & moving each of it's faces in turn (only with a single thing
+// selected)
+func (editor *gameEditor) cycleFace() {
+	if len(editor.selection) != 1 {
+		return
+	}
+
+	editor.face = (editor.face + 1) % editorFaceCount
+	editor.transform = nil
+}
+
+// the inputs the player is moved with - while something is selected the movement keys move it instead
+func (editor *gameEditor) playerInputs(game *Game, inputs map[GameInput]bool) map[GameInput]bool {
+	if !game.IsEditModeEnabled || len(editor.selection) == 0 {
+		return inputs
+	}
+
+	playerInputs := make(map[GameInput]bool, len(inputs))
+	for input, isActive := range inputs {
+		playerInputs[input] = isActive
+	}
+	for _, input := range editorNudgeInputs {
+		delete(playerInputs, input)
+	}
+
+	return playerInputs
+}
+
+// world space direction (along a single axis) of a movement input - the movement keys move the selection relative to the
+// camera like they do the player
+func editorNudgeDirection(game *Game, inputs map[GameInput]bool) mgl32.Vec3 {
+	var dir mgl32.Vec3
+	if inputs[GameInputPlayerMoveLeft] {
+		dir[0]++
+	}
+	if inputs[GameInputPlayerMoveRight] {
+		dir[0]--
+	}
+	if inputs[GameInputPlayerMoveForward] {
+		dir[2]++
+	}
+	if inputs[GameInputPlayerMoveBack] {
+		dir[2]--
+	}
+
+	camera := game.camera.(*arcballCamera)
+	dir = mgl32.HomogRotate3DY(mgl32.DegToRad(180 + camera.yaw)).Mul4x1(dir.Vec4(0)).Vec3()
+
+	// snap to whichever of the x or z axes is closest
+	if f32Abs(dir.X()) > f32Abs(dir.Z()) {
+		dir = mgl32.Vec3{float32(math.Copysign(1, float64(dir.X()))), 0, 0}
+	} else if dir.Z() != 0 {
+		dir = mgl32.Vec3{0, 0, float32(math.Copysign(1, float64(dir.Z())))}
+	}
+
+	if inputs[GameInputEditModeMoveUp] {
+		dir[1]++
+	}
+	if inputs[GameInputEditModeMoveDown] {
+		dir[1]--
+	}
+
+	return dir
+}
+
+// moves the selection (or the current face of the selection) a step in the direction of the movement inputs - repeating every
+// editorNudgeInterval while they're held
+func (editor *gameEditor) nudgeSelection(game *Game, dt float32, inputs map[GameInput]bool) {
+	dir := editorNudgeDirection(game, inputs)
+	if dir.Len() == 0 {
+		editor.timeSinceNudge = editorNudgeInterval // the next press nudges straight away
+		return
+	}
+
+	editor.timeSinceNudge += dt
+	if editor.timeSinceNudge < editorNudgeInterval {
+		return
+	}
+	editor.timeSinceNudge = 0
+
+	// with a snap grid the selection moves a grid cell at a time (& whatever moved ends up on the grid)
+	step := editorNudgeStep
+	minDimension := editorMinDimension
+	if editor.gridSize > 0 {
+		step = editor.gridSize
+		minDimension = editor.gridSize
+	}
+	offset := dir.Mul(step)
+
+	isChanged := false
+	before := make([]blockData, len(editor.selection))
+	after := make([]blockData, len(editor.selection))
+	for i, target := range editor.selection {
+		before[i] = target.editBounds()
+		after[i] = nudgeBounds(before[i], editor.face, offset, editor.gridSize, minDimension)
+		isChanged = isChanged || after[i] != before[i]
+
+		if after[i] != before[i] && game.overlapsSpawn(after[i]) {
+			fmt.Printf("can't move %s into the player's spawn\n", target.describe())
+			return
+		}
+	}
+
+	if !isChanged {
+		return
+	}
+
+	// consecutive nudges are undone together
+	if editor.transform != nil && editor.history.isLatest(editor.transform) {
+		editor.transform.after = after
+		editor.transform.do(game)
+		return
+	}
+
+	editor.transform = &transformCommand{targets: append([]editorTarget(nil), editor.selection...), before: before, after: after}
+	editor.history.execute(game, editor.transform)
+}
+
+// bounds moved (or with the face moved) by the offset
+func nudgeBounds(before blockData, face editorFace, offset mgl32.Vec3, gridSize float32, minDimension float32) blockData {
+	after := before
+
+	if axis, isMax := face.axis(); axis < 0 {
+		for axis := 0; axis < 3; axis++ {
+			if offset[axis] != 0 {
+				after.Position[axis] = snapToGrid(before.Position[axis]+offset[axis], gridSize)
+			}
+		}
+	} else if isMax {
+		max := snapToGrid(before.Position[axis]+before.Dimensions[axis]+offset[axis], gridSize)
+		after.Dimensions[axis] = f32Max(max-before.Position[axis], minDimension)
+	} else {
+		// moving the min face keeps the max face where it is
+		max := before.Position[axis] + before.Dimensions[axis]
+		min := f32Min(snapToGrid(before.Position[axis]+offset[axis], gridSize), max-minDimension)
+		after.Position[axis] = min
+		after.Dimensions[axis] = max - min
+	}
+
+	return after
+}
+
+func (editor *gameEditor) renderSelection(game *Game, viewMatrix mgl32.Mat4) error {
+	for _, target := range editor.selection {
+		bounds := boundsOf(target)
+		pos := bounds.min.Add(bounds.max).Mul(0.5)
+		scale := bounds.max.Sub(bounds.min).Mul(0.5)
+
+		// slightly bigger than the selection so the outline isn't hidden by it's faces
+		if err := renderWireBox(game, viewMatrix, pos, scale.Add(mgl32.Vec3{0.02, 0.02, 0.02}), editorSelectionColor); err != nil {
+			return err
+		}
+
+		// marks the face being moved
+		if axis, isMax := editor.face.axis(); axis >= 0 {
+			facePos := pos
+			facePos[axis] = bounds.min[axis]
+			if isMax {
+				facePos[axis] = bounds.max[axis]
+			}
+
+			if err := renderWireBox(game, viewMatrix, facePos, pathMarkerScale, editorSelectionColor); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
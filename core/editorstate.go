@@ -0,0 +1,120 @@
+package core
+
+import (
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// editorState the editor settings, clipboard & selection that are kept when the level restarts (& that a replay starts with).
+// the history & anything half placed are left behind
+type editorState struct {
+	IsEnabled       bool        `json:"enabled,omitempty"`
+	GridSize        float32     `json:"gridSize,omitempty"`
+	Material        string      `json:"material,omitempty"`
+	BlockKind       string      `json:"kind,omitempty"`
+	MotionPreset    int         `json:"motionPreset,omitempty"`
+	Face            editorFace  `json:"face,omitempty"`
+	Clipboard       *prefabData `json:"clipboard,omitempty"`
+	ClipboardOffset [3]float32  `json:"clipboardOffset,omitempty"`
+	World           []int       `json:"world,omitempty"`   // indexes of the selected world blocks
+	Enemies         []int       `json:"enemies,omitempty"` // indexes of the selected enemies
+}
+
+// the editor's current state (selection indexes are into the level as it is now)
+func (game *Game) saveEditorState() editorState {
+	editor := game.editor
+
+	state := editorState{
+		IsEnabled:    game.IsEditModeEnabled,
+		GridSize:     editor.gridSize,
+		Material:     editor.material,
+		BlockKind:    string(editor.blockKind),
+		MotionPreset: editor.motionPreset,
+		Face:         editor.face,
+	}
+
+	if editor.clipboard != nil {
+		clipboard := editor.clipboard.prefab.clone()
+		state.Clipboard = &clipboard
+		state.ClipboardOffset = [3]float32(editor.clipboard.offset)
+	}
+
+	for _, target := range editor.selection {
+		switch target := target.(type) {
+		case *worldBlock:
+			if i := game.worldBlockIndex(target); i >= 0 {
+				state.World = append(state.World, i)
+			}
+		case *enemy:
+			if i := game.enemyIndex(target); i >= 0 {
+				state.Enemies = append(state.Enemies, i)
+			}
+		}
+	}
+
+	return state
+}
+
+// replaces the editor with a new one (with an empty history) in the given state. anything selected that isn't in the level is
+// left unselected
+func (game *Game) loadEditorState(state editorState) {
+	editor := new(gameEditor)
+	editor.gridSize = state.GridSize
+	editor.material = state.Material
+	editor.blockKind = blockKind(state.BlockKind)
+	editor.motionPreset = state.MotionPreset
+
+	if state.Clipboard != nil {
+		editor.clipboard = &editorClipboard{prefab: state.Clipboard.clone(), offset: mgl32.Vec3(state.ClipboardOffset)}
+	}
+
+	for _, i := range state.World {
+		if i >= 0 && i < len(game.worldBlocks) {
+			editor.selection = append(editor.selection, game.worldBlocks[i])
+		}
+	}
+	for _, i := range state.Enemies {
+		if i >= 0 && i < len(game.enemies) {
+			editor.selection = append(editor.selection, game.enemies[i])
+		}
+	}
+	if len(editor.selection) == 1 {
+		editor.face = state.Face
+	}
+
+	editor.forgetMissingMaterial(game)
+
+	game.editor = editor
+	game.IsEditModeEnabled = state.IsEnabled
+}
+
+// checks the state can be loaded without breaking the editor (ex. when it's from an imported replay)
+func (state editorState) validate() error {
+	if !isEditorGridSize(state.GridSize) {
+		return fmt.Errorf("unknown snap grid size %v", state.GridSize)
+	}
+	if state.BlockKind != "" {
+		if _, isFound := blockKinds[blockKind(state.BlockKind)]; !isFound {
+			return fmt.Errorf("unknown block kind '%s'", state.BlockKind)
+		}
+	}
+	if state.MotionPreset < 0 || state.MotionPreset >= len(editorMotionPresets) {
+		return fmt.Errorf("unknown motion preset %d", state.MotionPreset)
+	}
+	if state.Face < editorFaceNone || state.Face >= editorFaceCount {
+		return fmt.Errorf("unknown face %d", state.Face)
+	}
+
+	return nil
+}
+
+func isEditorGridSize(gridSize float32) bool {
+	for _, size := range editorGridSizes {
+		if size == gridSize {
+			return true
+		}
+	}
+
+	return false
+}
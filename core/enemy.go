@@ -8,15 +8,98 @@ import (
 
 const enemyAcceleration float32 = playerAcceleration * .75
 
-var enemyColorDefault = mgl32.Vec4{1.0, .3, .3, 1.0}
+// how often (ms) an enemy re-plans it's path to the player
+const enemyReplanInterval float32 = 500
+
+// how close (horizontally) an enemy needs to get to the spot it's heading to before moving on to the next one
+const enemyWaypointRadius float32 = 0.3
+
 var enemyColorHighlighted = mgl32.Vec4{.99, .84, .20, 1.0}
 
 type enemy struct {
-	start mgl32.Vec3
-	pos   mgl32.Vec3
-	scale mgl32.Vec3
-	vel   mgl32.Vec3
-	color mgl32.Vec4
+	data     blockData // the enemy's start as authored in the level (exported as is)
+	start    mgl32.Vec3
+	pos      mgl32.Vec3
+	prevPos  mgl32.Vec3 // position at the start of the last simulation step
+	scale    mgl32.Vec3
+	vel      mgl32.Vec3
+	color    mgl32.Vec4
+	contacts contactState // faces touched during the last simulation step
+
+	kind            enemyKind
+	behavior        enemyBehavior
+	speed           float32    // max horizontal speed
+	detectionRadius float32    // how close the player needs to be to be noticed (0 for anywhere)
+	baseColor       mgl32.Vec4 // color when not highlighted
+	waypoints       []mgl32.Vec3
+	pathMode        enemyPathMode // how the waypoints are walked
+	waypointIndex   int           // index of the waypoint currently being headed to
+	isReturning     bool          // whether a ping-pong path is being walked backwards
+
+	path            []navEdge // moves left to reach the current target
+	pathGoal        *navNode  // node the current path leads to
+	timeSinceReplan float32
+}
+
+// sets the enemy's kind & resets it's behavior, speed, detection radius & color to the kind's defaults
+func (enemy *enemy) setKind(kind enemyKind) {
+	archetype := enemyArchetypes[kind]
+
+	enemy.kind = kind
+	enemy.behavior = archetype.behavior
+	enemy.speed = archetype.speed
+	enemy.detectionRadius = archetype.detectionRadius
+	enemy.baseColor = archetype.color
+	enemy.color = archetype.color
+}
+
+// puts the enemy back at it's start (as if play had just started)
+func (enemy *enemy) reset() {
+	enemy.pos = enemy.start
+	enemy.prevPos = enemy.start
+	enemy.vel = mgl32.Vec3{}
+	enemy.contacts = contactState{}
+	enemy.path = nil
+	enemy.pathGoal = nil
+	enemy.timeSinceReplan = 0
+	enemy.waypointIndex = 0
+	enemy.isReturning = false
+	enemy.color = enemy.baseColor
+}
+
+// adds an enemy to the game at the given index of game.enemies
+func (game *Game) insertEnemy(enemy *enemy, index int) {
+	game.enemies = append(game.enemies, nil)
+	copy(game.enemies[index+1:], game.enemies[index:])
+	game.enemies[index] = enemy
+}
+
+// removes an enemy from the game
+func (game *Game) removeEnemy(enemy *enemy) {
+	for i, existing := range game.enemies {
+		if existing == enemy {
+			copy(game.enemies[i:], game.enemies[i+1:])
+			game.enemies[len(game.enemies)-1] = nil
+			game.enemies = game.enemies[:len(game.enemies)-1]
+			return
+		}
+	}
+}
+
+// index of the enemy in the level (-1 if it's not in the level)
+func (game *Game) enemyIndex(enemy *enemy) int {
+	for i, existing := range game.enemies {
+		if existing == enemy {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// position interpolated between the last two simulation steps
+func (enemy *enemy) interpolatedPos(t float32) mgl32.Vec3 {
+	return lerpVec3(enemy.prevPos, enemy.pos, t)
 }
 
 func (enemy *enemy) velocity() mgl32.Vec3 {
@@ -48,34 +131,39 @@ func (enemy *enemy) back() float32 {
 }
 
 func (enemy *enemy) update(game *Game, dt float32, inputs map[GameInput]bool) {
-	playerPos := game.player.pos
 	enemyPos := enemy.pos
 
-	if game.IsEditModeEnabled {
-		enemy.pos = enemy.start
+	if game.IsEditModeEnabled || enemy.behavior.isStationary() {
+		enemy.reset()
+	}
 
+	if game.IsEditModeEnabled {
 		if checkForStaticOnStaticCollision(game.player, enemy) {
 			enemy.color = enemyColorHighlighted
-			game.Log += fmt.Sprintf("<br/>Enemy: (x: %.2f\ty: %.2f\tz: %.2f)", enemy.pos.X(), enemy.pos.Y(), enemy.pos.Z())
-		} else {
-			enemy.color = enemyColorDefault
+			game.Log += fmt.Sprintf("<br/>Enemy (%s): (x: %.2f\ty: %.2f\tz: %.2f)", enemy.kind, enemy.pos.X(), enemy.pos.Y(), enemy.pos.Z())
 		}
 
 		return
 	}
 
+	if enemy.behavior.isStationary() {
+		return
+	}
+
+	targetPos, isMoving, shouldJump := enemy.behavior.steer(enemy, game, dt)
+
 	var dvx, dvy, dvz float32
-	if playerPos.X() > enemyPos.X() {
+	if isMoving && targetPos.X() > enemyPos.X() {
 		dvx = enemyAcceleration
-	} else if playerPos.X() < enemyPos.X() {
+	} else if isMoving && targetPos.X() < enemyPos.X() {
 		dvx = -1 * enemyAcceleration
 	} else if enemy.vel.X() != 0 {
 		dvx = -1 * enemy.vel.X() / f32Abs(enemy.vel.X()) * dampening
 	}
 
-	if playerPos.Z() > enemyPos.Z() {
+	if isMoving && targetPos.Z() > enemyPos.Z() {
 		dvz = enemyAcceleration
-	} else if playerPos.Z() < enemyPos.Z() {
+	} else if isMoving && targetPos.Z() < enemyPos.Z() {
 		dvz = -1 * enemyAcceleration
 	} else if enemy.vel.Z() != 0 {
 		dvz = -1 * enemy.vel.Z() / f32Abs(enemy.vel.Z()) * dampening
@@ -84,24 +172,71 @@ func (enemy *enemy) update(game *Game, dt float32, inputs map[GameInput]bool) {
 	dvy = -1 * gravityAcceleration
 
 	enemy.vel = enemy.vel.Add(mgl32.Vec3{dvx, dvy, dvz})
-	enemy.vel[0] = f32LimitBetween(enemy.vel[0], -1*maxVelocity, maxVelocity)
-	enemy.vel[1] = f32LimitBetween(enemy.vel[1], -1*maxVelocity, maxVelocity)
-	enemy.vel[2] = f32LimitBetween(enemy.vel[2], -1*maxVelocity, maxVelocity)
+	enemy.vel[0] = f32LimitBetween(enemy.vel[0], -1*enemy.speed, enemy.speed)
+	enemy.vel[1] = f32Max(enemy.vel[1], -1*terminalVelocity) // terminal velocity
+	enemy.vel[2] = f32LimitBetween(enemy.vel[2], -1*enemy.speed, enemy.speed)
 
 	dPos := enemy.vel.Mul(dt / 1000)
-	for _, worldBlock := range game.worldBlocks {
-		if checkForDynamicOnStaticCollision(dPos, enemy, worldBlock) {
-			dPos = processDynamicOnStaticCollisionDetails(dt, dPos, enemy, worldBlock)
+	var contacts []collisionContact
+	dPos, contacts = resolveDynamicOnStaticCollisions(dPos, enemy, game.worldIndex.query(boundsOf(enemy).expand(dPos)))
+	enemy.contacts = newContactState(contacts, enemy.contacts)
+	enemy.vel = enemy.contacts.clampVelocity(enemy.vel)
+
+	enemy.pos = enemy.pos.Add(dPos)
+	enemy.color = enemy.baseColor
+
+	if shouldJump && enemy.contacts.grounded {
+		enemy.vel[1] = jumpVelocity
+	}
+}
+
+// bottom center of the enemy
+func (enemy *enemy) feetPos() mgl32.Vec3 {
+	return mgl32.Vec3{enemy.pos.X(), enemy.bottom(), enemy.pos.Z()}
+}
+
+// picks the position the enemy should head towards to follow a path to the target (who's bottom is at targetBottom) & whether it
+// should jump
+func (enemy *enemy) followPathTo(game *Game, dt float32, target mgl32.Vec3, targetBottom float32) (mgl32.Vec3, bool) {
+	navigation := game.navigation()
+	goal := navigation.nodeAt(mgl32.Vec3{target.X(), targetBottom, target.Z()})
+
+	// only re-plan from the ground - mid jump the enemy isn't over the spot it's heading from
+	enemy.timeSinceReplan += dt
+	isReplanNeeded := enemy.path == nil || goal != enemy.pathGoal || enemy.timeSinceReplan >= enemyReplanInterval
+	if enemy.contacts.grounded && isReplanNeeded {
+		enemy.path = navigation.findPath(navigation.nodeAt(enemy.feetPos()), goal)
+		enemy.pathGoal = goal
+		enemy.timeSinceReplan = 0
+	}
+
+	for len(enemy.path) > 0 {
+		next := enemy.path[0].to.pos
+		horizontalDistance := mgl32.Vec2{next.X() - enemy.pos.X(), next.Z() - enemy.pos.Z()}.Len()
+		if horizontalDistance > enemyWaypointRadius || f32Abs(next.Y()-enemy.bottom()) > collisionEpsilon {
+			break
 		}
+
+		enemy.path = enemy.path[1:]
 	}
 
-	enemy.pos = enemy.pos.Add(dPos)
-	enemy.color = enemyColorDefault
+	// on the same spot as the target (or no way to reach it) - just head straight for it
+	if len(enemy.path) == 0 {
+		return target, false
+	}
+
+	edge := enemy.path[0]
+	horizontalDistance := mgl32.Vec2{edge.to.pos.X() - enemy.pos.X(), edge.to.pos.Z() - enemy.pos.Z()}.Len()
+	shouldJump := edge.jump && (enemy.contacts.touchingWall() || horizontalDistance <= 1.5*navCellSize)
+
+	return edge.to.pos, shouldJump
 }
 
 func (enemy *enemy) render(game *Game, viewMatrix mgl32.Mat4) error {
+	pos := enemy.interpolatedPos(game.interpolation)
+
 	scaleMatrix := mgl32.Scale3D(enemy.scale.X(), enemy.scale.Y(), enemy.scale.Z())
-	translateMatrix := mgl32.Translate3D(enemy.pos.X(), enemy.pos.Y(), enemy.pos.Z())
+	translateMatrix := mgl32.Translate3D(pos.X(), pos.Y(), pos.Z())
 
 	modelMatrix := mgl32.Ident4().Mul4(translateMatrix).Mul4(scaleMatrix)
 
@@ -110,7 +245,7 @@ func (enemy *enemy) render(game *Game, viewMatrix mgl32.Mat4) error {
 	game.normalMatrix = game.modelViewMatrix.Inv().Transpose()
 	game.color = enemy.color
 	game.material = mgl32.Vec4{0.4, 0.7, 1.0, 50.0}
-	game.lightPos = game.player.pos
+	game.lightPos = game.player.interpolatedPos(game.interpolation)
 
 	if err := game.gl.RenderTriangles(game.blockMesh, game.phongShader); err != nil {
 		return err
@@ -0,0 +1,223 @@
+package core
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// enemyKind the archetype of an enemy (as named in the level data)
+type enemyKind string
+
+const (
+	// chases the player anywhere (following a path over the world blocks)
+	enemyKindChaser enemyKind = "chaser"
+	// walks it's waypoints (in a loop or back & forth - see enemyPathMode)
+	enemyKindPatroller enemyKind = "patroller"
+	// chases the player only when they're within it's detection radius & in sight - otherwise heads back to it's start
+	enemyKindSentry enemyKind = "sentry"
+	// hops straight towards the player (jumping onto any blocks in the way)
+	enemyKindJumper enemyKind = "jumper"
+	// never moves
+	enemyKindHazard enemyKind = "hazard"
+)
+
+// enemyPathMode how a patroller walks it's waypoints (as named in the level data)
+type enemyPathMode string
+
+const (
+	// from the last waypoint straight back to the first
+	enemyPathModeLoop enemyPathMode = "loop"
+	// back & forth - turning around at the first & last waypoints
+	enemyPathModePingPong enemyPathMode = "pingpong"
+)
+
+func isValidEnemyPathMode(pathMode enemyPathMode) bool {
+	return pathMode == enemyPathModeLoop || pathMode == enemyPathModePingPong
+}
+
+// the other path mode (for toggling between them)
+func nextEnemyPathMode(pathMode enemyPathMode) enemyPathMode {
+	if pathMode == enemyPathModePingPong {
+		return enemyPathModeLoop
+	}
+
+	return enemyPathModePingPong
+}
+
+// how close (horizontally) a patroller needs to get to a waypoint before heading to the next one
+const enemyPatrolWaypointRadius float32 = 0.5
+
+// enemyArchetype the defaults for a kind of enemy (speed, detection radius & color can be overridden per enemy in the level data)
+type enemyArchetype struct {
+	behavior        enemyBehavior
+	speed           float32
+	detectionRadius float32
+	color           mgl32.Vec4
+}
+
+var enemyArchetypes = map[enemyKind]enemyArchetype{
+	enemyKindChaser: {
+		behavior: chaserBehavior{},
+		speed:    maxVelocity,
+		color:    mgl32.Vec4{1.0, .3, .3, 1.0},
+	},
+	enemyKindPatroller: {
+		behavior: patrollerBehavior{},
+		speed:    4,
+		color:    mgl32.Vec4{1.0, .55, .1, 1.0},
+	},
+	enemyKindSentry: {
+		behavior:        sentryBehavior{},
+		speed:           8,
+		detectionRadius: 8,
+		color:           mgl32.Vec4{.75, .2, .75, 1.0},
+	},
+	enemyKindJumper: {
+		behavior: jumperBehavior{},
+		speed:    6,
+		color:    mgl32.Vec4{.3, .85, .3, 1.0},
+	},
+	enemyKindHazard: {
+		behavior: hazardBehavior{},
+		color:    mgl32.Vec4{.5, .05, .05, 1.0},
+	},
+}
+
+func isValidEnemyKind(kind enemyKind) bool {
+	_, isValid := enemyArchetypes[kind]
+	return isValid
+}
+
+// enemyBehavior decides where an enemy heads each simulation step
+type enemyBehavior interface {
+	// returns the position to head towards, whether to move at all & whether to jump
+	steer(enemy *enemy, game *Game, dt float32) (mgl32.Vec3, bool, bool)
+	// whether the enemy stays fixed at it's start position
+	isStationary() bool
+}
+
+type chaserBehavior struct{}
+
+func (chaserBehavior) steer(enemy *enemy, game *Game, dt float32) (mgl32.Vec3, bool, bool) {
+	if !enemy.canDetect(game.player) {
+		return enemy.pos, false, false
+	}
+
+	targetPos, shouldJump := enemy.followPathTo(game, dt, game.player.pos, game.player.bottom())
+	return targetPos, true, shouldJump
+}
+
+func (chaserBehavior) isStationary() bool {
+	return false
+}
+
+type patrollerBehavior struct{}
+
+func (patrollerBehavior) steer(enemy *enemy, game *Game, dt float32) (mgl32.Vec3, bool, bool) {
+	if len(enemy.waypoints) == 0 {
+		return enemy.pos, false, false
+	}
+
+	waypoint := enemy.waypoints[enemy.waypointIndex]
+	horizontalDistance := mgl32.Vec2{waypoint.X() - enemy.pos.X(), waypoint.Z() - enemy.pos.Z()}.Len()
+	if horizontalDistance <= enemyPatrolWaypointRadius && f32Abs(waypoint.Y()-enemy.pos.Y()) <= navAgentHeight {
+		enemy.nextWaypoint()
+		waypoint = enemy.waypoints[enemy.waypointIndex]
+	}
+
+	targetPos, shouldJump := enemy.followPathTo(game, dt, waypoint, waypoint.Y()-enemy.scale.Y())
+	return targetPos, true, shouldJump
+}
+
+func (patrollerBehavior) isStationary() bool {
+	return false
+}
+
+// moves on to the next waypoint - wrapping around to the first for loops or turning around at the ends for ping-pong
+func (enemy *enemy) nextWaypoint() {
+	if len(enemy.waypoints) < 2 {
+		return
+	}
+
+	if enemy.pathMode != enemyPathModePingPong {
+		enemy.waypointIndex = (enemy.waypointIndex + 1) % len(enemy.waypoints)
+		return
+	}
+
+	if enemy.waypointIndex == len(enemy.waypoints)-1 {
+		enemy.isReturning = true
+	} else if enemy.waypointIndex == 0 {
+		enemy.isReturning = false
+	}
+
+	if enemy.isReturning {
+		enemy.waypointIndex--
+	} else {
+		enemy.waypointIndex++
+	}
+}
+
+type sentryBehavior struct{}
+
+func (sentryBehavior) steer(enemy *enemy, game *Game, dt float32) (mgl32.Vec3, bool, bool) {
+	if enemy.canDetect(game.player) && game.hasLineOfSight(enemy.pos, game.player.pos) {
+		targetPos, shouldJump := enemy.followPathTo(game, dt, game.player.pos, game.player.bottom())
+		return targetPos, true, shouldJump
+	}
+
+	// lost the player - head back to our post
+	horizontalDistance := mgl32.Vec2{enemy.start.X() - enemy.pos.X(), enemy.start.Z() - enemy.pos.Z()}.Len()
+	if horizontalDistance <= enemyWaypointRadius {
+		return enemy.pos, false, false
+	}
+
+	targetPos, shouldJump := enemy.followPathTo(game, dt, enemy.start, enemy.start.Y()-enemy.scale.Y())
+	return targetPos, true, shouldJump
+}
+
+func (sentryBehavior) isStationary() bool {
+	return false
+}
+
+type jumperBehavior struct{}
+
+func (jumperBehavior) steer(enemy *enemy, game *Game, dt float32) (mgl32.Vec3, bool, bool) {
+	if !enemy.canDetect(game.player) {
+		return enemy.pos, false, false
+	}
+
+	// no path finding - just keep hopping at the player
+	return game.player.pos, true, true
+}
+
+func (jumperBehavior) isStationary() bool {
+	return false
+}
+
+type hazardBehavior struct{}
+
+func (hazardBehavior) steer(enemy *enemy, game *Game, dt float32) (mgl32.Vec3, bool, bool) {
+	return enemy.pos, false, false
+}
+
+func (hazardBehavior) isStationary() bool {
+	return true
+}
+
+// whether the player is within the enemy's detection radius
+func (enemy *enemy) canDetect(player *player) bool {
+	return enemy.detectionRadius <= 0 || player.pos.Sub(enemy.pos).Len() <= enemy.detectionRadius
+}
+
+// checks that no world block is in the way between 2 positions
+func (game *Game) hasLineOfSight(from, to mgl32.Vec3) bool {
+	dir := to.Sub(from)
+	segment := bounds{min: from, max: from}.expand(dir)
+
+	for _, worldBlock := range game.worldIndex.query(segment) {
+		if t, _, isHit := intersectRayWithBounds(from, dir, boundsOf(worldBlock)); isHit && t < 1 {
+			return false
+		}
+	}
+
+	return true
+}
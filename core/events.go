@@ -0,0 +1,108 @@
+package core
+
+// GameEventType the type of a game event
+type GameEventType int
+
+const (
+	// GameEventCheckpoint the player reached a new checkpoint
+	GameEventCheckpoint GameEventType = iota + 1
+	// GameEventRespawn the player died & was sent back to the last checkpoint
+	GameEventRespawn
+	// GameEventGameOver the player died with no lives left
+	GameEventGameOver
+	// GameEventLevelComplete the player reached a goal
+	GameEventLevelComplete
+	// GameEventTriggerEnter the player or an enemy started overlapping a trigger volume
+	GameEventTriggerEnter
+	// GameEventTriggerStay the player or an enemy is still overlapping a trigger volume (sent every simulation step after entering)
+	GameEventTriggerStay
+	// GameEventTriggerExit the player or an enemy stopped overlapping a trigger volume
+	GameEventTriggerExit
+	// GameEventDeath the player was killed (before a life is taken)
+	GameEventDeath
+	// GameEventMessage the player entered a trigger volume with a message
+	GameEventMessage
+	// GameEventTouch the player is touching a world block or enemy (sent every simulation step while touching)
+	GameEventTouch
+)
+
+// GameEvent something that happened in the game that other systems or the host may want to react to (ex. by updating it's UI)
+type GameEvent struct {
+	Type       GameEventType
+	Lives      int    // lives left when the event was published (death events are published before the life is taken)
+	Checkpoint int    // index of the player's current checkpoint in the level (-1 for the level's spawn)
+	Trigger    int    // index of the trigger volume in the level (trigger & message events - -1 for a checkpoint's volume)
+	Block      int    // index of the world block touched (touch, death & level complete events - -1 otherwise)
+	Enemy      int    // index of the enemy that set off the trigger or was touched (-1 for the player or none)
+	Message    string // message of the trigger volume (message events)
+	Cause      string // what killed the player (death events - "fell", "enemy" or "block")
+
+	trigger    *trigger    // the trigger volume (trigger & message events)
+	worldBlock *worldBlock // the world block touched (touch events)
+}
+
+// GameEventHandler handles an event as it's published
+type GameEventHandler func(event GameEvent)
+
+type eventSubscription struct {
+	id        int
+	eventType GameEventType
+	handler   GameEventHandler
+}
+
+// eventBus dispatches events to the handlers subscribed to them
+type eventBus struct {
+	subscriptions []eventSubscription
+	lastID        int
+}
+
+func (bus *eventBus) subscribe(eventType GameEventType, handler GameEventHandler) int {
+	bus.lastID++
+	bus.subscriptions = append(bus.subscriptions, eventSubscription{id: bus.lastID, eventType: eventType, handler: handler})
+
+	return bus.lastID
+}
+
+func (bus *eventBus) unsubscribe(id int) {
+	for i, subscription := range bus.subscriptions {
+		if subscription.id == id {
+			// copied so a publish already iterating over the subscriptions isn't affected
+			bus.subscriptions = append(bus.subscriptions[:i:i], bus.subscriptions[i+1:]...)
+			return
+		}
+	}
+}
+
+// calls the handlers subscribed to the event's type (in the order they subscribed). handlers may (un)subscribe or publish
+// events of their own
+func (bus *eventBus) publish(event GameEvent) {
+	for _, subscription := range bus.subscriptions {
+		if subscription.eventType == event.Type {
+			subscription.handler(event)
+		}
+	}
+}
+
+// Subscribe calls the handler for every event of the given type as it's published (during Update). returns an id that can be
+// passed to Unsubscribe
+func (game *Game) Subscribe(eventType GameEventType, handler GameEventHandler) int {
+	return game.eventBus.subscribe(eventType, handler)
+}
+
+// Unsubscribe stops calling the handler with the given id (from Subscribe)
+func (game *Game) Unsubscribe(id int) {
+	game.eventBus.unsubscribe(id)
+}
+
+// publishes the event to it's subscribers
+func (game *Game) publish(event GameEvent) {
+	event.Lives = game.lives
+	event.Checkpoint = game.checkpointIndex
+
+	game.eventBus.publish(event)
+}
+
+// publishes an event with no details beyond it's type
+func (game *Game) emit(eventType GameEventType) {
+	game.publish(GameEvent{Type: eventType, Trigger: -1, Block: -1, Enemy: -1})
+}
@@ -12,10 +12,42 @@ type blockData struct {
 	Dimensions [3]float32 `json:"dimensions"`
 }
 
+type worldBlockData struct {
+	blockData
+	Color            *[4]float32  `json:"color,omitempty"`
+	Material         string       `json:"material,omitempty"` // name of a material in the level's materials table (or a builtin material)
+	Kind             string       `json:"kind,omitempty"`
+	ConveyorVelocity *[3]float32  `json:"conveyorVelocity,omitempty"` // only used by conveyor blocks
+	Motion           *motionData  `json:"motion,omitempty"`           // nil for static blocks
+	Crumble          *crumbleData `json:"crumble,omitempty"`
+	Timer            *timerData   `json:"timer,omitempty"`
+}
+
+type enemyData struct {
+	blockData
+	Type            string       `json:"type,omitempty"`
+	Speed           *float32     `json:"speed,omitempty"`
+	DetectionRadius *float32     `json:"detectionRadius,omitempty"`
+	Color           *[4]float32  `json:"color,omitempty"`
+	Waypoints       [][3]float32 `json:"waypoints,omitempty"`
+	PathMode        string       `json:"pathMode,omitempty"`
+}
+
 type gameData struct {
-	Player  blockData   `json:"player"`
-	World   []blockData `json:"world"`
-	Enemies []blockData `json:"enemies"`
+	Version     int                     `json:"version"`
+	Materials   map[string]materialData `json:"materials,omitempty"`
+	Player      blockData               `json:"player"`
+	Lives       int                     `json:"lives,omitempty"` // lives the player starts with (defaultLives if not set)
+	Checkpoints []blockData             `json:"checkpoints,omitempty"`
+	Triggers    []triggerData           `json:"triggers,omitempty"`
+	World       []worldBlockData        `json:"world"`
+	Enemies     []enemyData             `json:"enemies"`
+	Prefabs     []prefabData            `json:"prefabs,omitempty"` // groups of world blocks & enemies the editor can place
+}
+
+// blockData describing the current bounds of the block
+func newBlockData(block collidable) blockData {
+	return blockData{Position: getBlockPosition(block), Dimensions: getBlockDimensions(block)}
 }
 
 func getBlockPosition(block collidable) mgl32.Vec3 {
@@ -34,69 +66,146 @@ func getBlockScaleFromData(data blockData) mgl32.Vec3 {
 	return mgl32.Vec3(data.Dimensions).Mul(0.5)
 }
 
-// ExportAsJSON exports the game into json data
+// enemyData describing the enemy as authored (it's start rather than where it currently is)
+func newEnemyData(enemy *enemy) enemyData {
+	var enemyData enemyData
+
+	enemyData.blockData = enemy.data
+	enemyData.Type = string(enemy.kind)
+
+	// only write out what differs from the enemy's archetype
+	archetype := enemyArchetypes[enemy.kind]
+	if enemy.speed != archetype.speed {
+		speed := enemy.speed
+		enemyData.Speed = &speed
+	}
+	if enemy.detectionRadius != archetype.detectionRadius {
+		detectionRadius := enemy.detectionRadius
+		enemyData.DetectionRadius = &detectionRadius
+	}
+	if enemy.baseColor != archetype.color {
+		color := [4]float32(enemy.baseColor)
+		enemyData.Color = &color
+	}
+
+	for _, waypoint := range enemy.waypoints {
+		enemyData.Waypoints = append(enemyData.Waypoints, waypoint)
+	}
+	if len(enemy.waypoints) > 0 && enemy.pathMode != enemyPathModeLoop {
+		enemyData.PathMode = string(enemy.pathMode)
+	}
+
+	return enemyData
+}
+
+// ExportAsJSON exports the level as authored (the player's spawn & enemies' starts rather than where they currently are) into json
+// data. importing the exported json results in an identical level
 func (game *Game) ExportAsJSON() string {
 	var data gameData
 
-	data.Player.Position = getBlockDimensions(game.player)
-	data.Player.Dimensions = getBlockDimensions(game.player)
+	data.Version = levelVersionCurrent
+	data.Materials = game.materials
+	data.Player = game.spawn
+	data.Lives = game.levelLives
 
-	data.World = make([]blockData, 0, len(game.worldBlocks))
-	for _, worldBlock := range game.worldBlocks {
-		var worldBlockData blockData
+	for _, checkpoint := range game.checkpoints {
+		data.Checkpoints = append(data.Checkpoints, checkpoint.data)
+	}
 
-		worldBlockData.Position = getBlockPosition(worldBlock)
-		worldBlockData.Dimensions = getBlockDimensions(worldBlock)
+	for _, trigger := range game.triggers {
+		if trigger.index >= 0 {
+			data.Triggers = append(data.Triggers, trigger.data)
+		}
+	}
 
-		data.World = append(data.World, worldBlockData)
+	data.World = make([]worldBlockData, 0, len(game.worldBlocks))
+	for _, worldBlock := range game.worldBlocks {
+		data.World = append(data.World, worldBlock.data)
 	}
 
-	data.Enemies = make([]blockData, 0, len(game.enemies))
+	data.Enemies = make([]enemyData, 0, len(game.enemies))
 	for _, enemy := range game.enemies {
-		var enemyData blockData
-
-		enemyData.Position = getBlockPosition(enemy)
-		enemyData.Dimensions = getBlockDimensions(enemy)
-
-		data.Enemies = append(data.Enemies, enemyData)
+		data.Enemies = append(data.Enemies, newEnemyData(enemy))
 	}
 
+	data.Prefabs = game.prefabs
+
 	json, _ := json.Marshal(&data)
 
 	return string(json)
 }
 
-// ImportFromJSON imports the game from json data. returns worldBlockIDs enemyIds or an error
+// ImportFromJSON imports the game from json data. levels from older versions are migrated to the current version first. returns
+// LevelValidationErrors if the level isn't playable (the game is left untouched if there's any error)
 func (game *Game) ImportFromJSON(jsonData string) error {
 	var data gameData
 
-	if err := json.Unmarshal([]byte(jsonData), &data); err != nil {
+	migratedJSON, err := migrateLevel(jsonData)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(migratedJSON, &data); err != nil {
+		return err
+	}
+
+	if err := validateLevel(&data); err != nil {
 		return err
 	}
 
+	game.levelJSON = jsonData
+
+	// changes to the last level can't be undone in this one
+	game.editor.history = editorHistory{}
+
+	// importing a level starts it over
+	game.IsGameOver = false
+	game.IsLevelComplete = false
+
+	game.spawn = data.Player
+	game.materials = data.Materials
+	game.editor.forgetMissingMaterial(game)
+	game.prefabs = data.Prefabs
+	game.time = 0
 	game.player.pos = getBlockPosFromData(data.Player)
+	game.player.prevPos = game.player.pos
+
+	game.levelLives = data.Lives
+	game.resetLives()
+	game.resetStats()
+
+	game.triggers = make([]*trigger, 0, len(data.Triggers)+len(data.Checkpoints))
+	for i, triggerData := range data.Triggers {
+		game.triggers = append(game.triggers, newTrigger(triggerData, i, -1))
+	}
+
+	game.checkpoints = make([]*checkpoint, 0, len(data.Checkpoints))
+	for i, checkpointData := range data.Checkpoints {
+		checkpoint := new(checkpoint)
+
+		checkpoint.data = checkpointData
+		checkpoint.pos = getBlockPosFromData(checkpointData)
+		checkpoint.scale = getBlockScaleFromData(checkpointData)
+
+		game.checkpoints = append(game.checkpoints, checkpoint)
+		game.triggers = append(game.triggers, newTrigger(triggerData{blockData: checkpointData}, -1, i))
+	}
 
 	fmt.Printf("Imported Player - Pos: {x: %.2f, y: %.2f, z: %.2f}\n", game.player.pos.X(), game.player.pos.Y(), game.player.pos.Z())
 
 	game.worldBlocks = make([]*worldBlock, 0, len(data.World))
+	game.worldIndex = newSpatialHash(spatialHashCellSize)
 	for _, worldBlockData := range data.World {
-		worldBlock := new(worldBlock)
+		worldBlock := newWorldBlockFromData(game, worldBlockData)
 
-		worldBlock.pos = getBlockPosFromData(worldBlockData)
-		worldBlock.scale = getBlockScaleFromData(worldBlockData)
-
-		game.worldBlocks = append(game.worldBlocks, worldBlock)
+		game.addWorldBlock(worldBlock)
 
 		fmt.Printf("Imported WorldBlock - Pos: {x: %.2f, y: %.2f, z: %.2f} - Scale: {x: %.2f, y: %.2f, z: %.2f}\n", worldBlock.pos.X(), worldBlock.pos.Y(), worldBlock.pos.Z(), worldBlock.scale.X(), worldBlock.scale.Y(), worldBlock.scale.Z())
 	}
 
 	game.enemies = make([]*enemy, 0, len(data.Enemies))
 	for _, enemyData := range data.Enemies {
-		enemy := new(enemy)
-
-		enemy.pos = getBlockPosFromData(enemyData)
-		enemy.scale = getBlockScaleFromData(enemyData)
-		enemy.start = enemy.pos
+		enemy := newEnemyFromData(enemyData)
 
 		game.enemies = append(game.enemies, enemy)
 
@@ -105,3 +214,55 @@ func (game *Game) ImportFromJSON(jsonData string) error {
 
 	return nil
 }
+
+// world block as authored in the level data
+func newWorldBlockFromData(game *Game, data worldBlockData) *worldBlock {
+	worldBlock := new(worldBlock)
+
+	worldBlock.data = data
+	worldBlock.place(getBlockPosFromData(data.blockData))
+	worldBlock.scale = getBlockScaleFromData(data.blockData)
+	worldBlock.applyData(game)
+
+	return worldBlock
+}
+
+// enemy as authored in the level data
+func newEnemyFromData(enemyData enemyData) *enemy {
+	enemy := new(enemy)
+
+	kind := enemyKind(enemyData.Type)
+	if kind == "" {
+		kind = enemyKindChaser
+	}
+
+	pathMode := enemyPathMode(enemyData.PathMode)
+	if pathMode == "" {
+		pathMode = enemyPathModeLoop
+	}
+
+	enemy.data = enemyData.blockData
+	enemy.pos = getBlockPosFromData(enemyData.blockData)
+	enemy.scale = getBlockScaleFromData(enemyData.blockData)
+	enemy.setKind(kind)
+
+	if enemyData.Speed != nil {
+		enemy.speed = *enemyData.Speed
+	}
+	if enemyData.DetectionRadius != nil {
+		enemy.detectionRadius = *enemyData.DetectionRadius
+	}
+	if enemyData.Color != nil {
+		enemy.baseColor = mgl32.Vec4(*enemyData.Color)
+		enemy.color = enemy.baseColor
+	}
+
+	for _, waypoint := range enemyData.Waypoints {
+		enemy.waypoints = append(enemy.waypoints, mgl32.Vec3(waypoint))
+	}
+	enemy.pathMode = pathMode
+	enemy.prevPos = enemy.pos
+	enemy.start = enemy.pos
+
+	return enemy
+}
@@ -0,0 +1,375 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+// nullGl a GlContext that draws nothing (the headless package's contexts can't be imported here - they import core)
+type nullGl struct{}
+
+func (gl nullGl) UpdateViewport()                             {}
+func (gl nullGl) GetViewportWidth() int                       { return 100 }
+func (gl nullGl) GetViewportHeight() int                      { return 100 }
+func (gl nullGl) Enable(string)                               {}
+func (gl nullGl) Disable(string)                              {}
+func (gl nullGl) ClearScreen(float32, float32, float32) error { return nil }
+func (gl nullGl) RenderTriangles(Mesh, ShaderProgram) error   { return nil }
+func (gl nullGl) RenderLines(Mesh, ShaderProgram) error       { return nil }
+
+func (gl nullGl) NewShaderProgram(string, string, map[string][]float32) (ShaderProgram, error) {
+	return nil, nil
+}
+
+func (gl nullGl) NewMesh([]float32, []float32, []uint16) (Mesh, error) {
+	return nil, nil
+}
+
+func newTestGame(t *testing.T) *Game {
+	game, err := NewGame(nullGl{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return game
+}
+
+func levelJSON(t *testing.T, data gameData) string {
+	levelJSON, err := json.Marshal(&data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(levelJSON)
+}
+
+// randomLevel a valid level with a bit of everything the level data can describe (for testing/quick)
+type randomLevel struct {
+	data gameData
+}
+
+func (randomLevel) Generate(rng *rand.Rand, size int) reflect.Value {
+	var data gameData
+
+	data.Version = levelVersionCurrent
+	data.Lives = rng.Intn(5)
+
+	// the spawn is above everything else so it never overlaps anything
+	data.Player = blockData{Position: [3]float32{randFloat(rng, -5, 5), 50, randFloat(rng, -5, 5)}, Dimensions: [3]float32{1, 1, 1}}
+
+	materialNames := []string{"", materialNameDefault, "shiny"}
+	for i := rng.Intn(3); i > 0; i-- {
+		if data.Materials == nil {
+			data.Materials = make(map[string]materialData)
+		}
+
+		name := fmt.Sprintf("material%d", i)
+		data.Materials[name] = materialData{
+			Ambient:   rng.Float32(),
+			Diffuse:   rng.Float32(),
+			Specular:  rng.Float32(),
+			Shininess: randFloat(rng, 0, 100),
+			Color:     randColor(rng),
+		}
+		materialNames = append(materialNames, name)
+	}
+
+	for i := rng.Intn(4); i > 0; i-- {
+		data.Checkpoints = append(data.Checkpoints, randBlockData(rng))
+	}
+
+	for i := rng.Intn(4); i > 0; i-- {
+		trigger := triggerData{blockData: randBlockData(rng)}
+		if rng.Intn(2) == 0 {
+			trigger.Name = fmt.Sprintf("trigger%d", i)
+			trigger.Message = fmt.Sprintf("message %d", i)
+		}
+		data.Triggers = append(data.Triggers, trigger)
+	}
+
+	data.World = randWorld(rng, size, materialNames)
+	data.Enemies = randEnemies(rng, size)
+
+	for i := rng.Intn(3); i > 0; i-- {
+		data.Prefabs = append(data.Prefabs, prefabData{
+			Name:    fmt.Sprintf("prefab%d", i),
+			World:   randWorld(rng, 3, materialNames),
+			Enemies: randEnemies(rng, 2),
+		})
+	}
+
+	return reflect.ValueOf(randomLevel{data: data})
+}
+
+// the level as ExportAsJSON writes it out (enemies always have a type & there's always a world & enemies list)
+func (level randomLevel) exported() gameData {
+	data := level.data
+
+	data.World = append([]worldBlockData{}, data.World...)
+
+	data.Enemies = append([]enemyData{}, data.Enemies...)
+	for i := range data.Enemies {
+		if data.Enemies[i].Type == "" {
+			data.Enemies[i].Type = string(enemyKindChaser)
+		}
+	}
+
+	return data
+}
+
+func randFloat(rng *rand.Rand, min, max float32) float32 {
+	return min + rng.Float32()*(max-min)
+}
+
+func randVec3(rng *rand.Rand, min, max float32) [3]float32 {
+	return [3]float32{randFloat(rng, min, max), randFloat(rng, min, max), randFloat(rng, min, max)}
+}
+
+func randColor(rng *rand.Rand) *[4]float32 {
+	if rng.Intn(2) == 0 {
+		return nil
+	}
+
+	return &[4]float32{rng.Float32(), rng.Float32(), rng.Float32(), 1}
+}
+
+// bounds somewhere below the spawn
+func randBlockData(rng *rand.Rand) blockData {
+	return blockData{Position: randVec3(rng, -20, 20), Dimensions: randVec3(rng, 0.25, 5)}
+}
+
+func randWorld(rng *rand.Rand, size int, materialNames []string) []worldBlockData {
+	var world []worldBlockData
+	for i := rng.Intn(size + 1); i > 0; i-- {
+		worldBlock := worldBlockData{blockData: randBlockData(rng), Color: randColor(rng)}
+		worldBlock.Material = materialNames[rng.Intn(len(materialNames))]
+
+		if kind := rng.Intn(len(blockKindNames) + 1); kind < len(blockKindNames) {
+			worldBlock.Kind = string(blockKindNames[kind])
+		}
+		if worldBlock.Kind == string(blockKindConveyor) && rng.Intn(2) == 0 {
+			velocity := randVec3(rng, -2, 2)
+			worldBlock.ConveyorVelocity = &velocity
+		}
+
+		switch rng.Intn(4) {
+		case 1:
+			worldBlock.Motion = &motionData{Type: string(blockMotionOscillate), Period: randFloat(rng, 1, 5), Amplitude: randVec3(rng, -3, 3)}
+		case 2:
+			worldBlock.Motion = &motionData{Type: string(blockMotionOrbit), Period: randFloat(rng, 1, 5), Phase: rng.Float32(), Radius: randFloat(rng, 1, 3)}
+		case 3:
+			worldBlock.Motion = &motionData{Type: string(blockMotionPath), PathMode: string(enemyPathModePingPong)}
+			time := float32(0)
+			for j := 2 + rng.Intn(3); j > 0; j-- {
+				worldBlock.Motion.Keyframes = append(worldBlock.Motion.Keyframes, keyframeData{Time: time, Offset: randVec3(rng, -3, 3)})
+				time += randFloat(rng, 0.5, 2)
+			}
+		}
+
+		switch rng.Intn(3) {
+		case 1:
+			worldBlock.Crumble = &crumbleData{Delay: rng.Float32(), Mode: string(crumbleModeFall), Respawn: randFloat(rng, 0, 5)}
+		case 2:
+			worldBlock.Timer = &timerData{Period: randFloat(rng, 1, 5), Duty: rng.Float32(), Phase: rng.Float32()}
+		}
+
+		world = append(world, worldBlock)
+	}
+
+	return world
+}
+
+func randEnemies(rng *rand.Rand, size int) []enemyData {
+	kinds := []enemyKind{"", enemyKindChaser, enemyKindPatroller, enemyKindSentry, enemyKindJumper, enemyKindHazard}
+
+	var enemies []enemyData
+	for i := rng.Intn(size + 1); i > 0; i-- {
+		enemy := enemyData{blockData: randBlockData(rng), Color: randColor(rng)}
+		enemy.Type = string(kinds[rng.Intn(len(kinds))])
+
+		if rng.Intn(2) == 0 {
+			speed := randFloat(rng, 0, 10)
+			enemy.Speed = &speed
+		}
+		if rng.Intn(2) == 0 {
+			detectionRadius := randFloat(rng, 0, 10)
+			enemy.DetectionRadius = &detectionRadius
+		}
+
+		// patrollers need a path
+		waypoints := rng.Intn(4)
+		if enemy.Type == string(enemyKindPatroller) && waypoints == 0 {
+			waypoints = 1
+		}
+		for j := 0; j < waypoints; j++ {
+			enemy.Waypoints = append(enemy.Waypoints, randVec3(rng, -20, 20))
+		}
+		if waypoints > 0 && rng.Intn(2) == 0 {
+			enemy.PathMode = string(enemyPathModePingPong)
+		}
+
+		enemies = append(enemies, enemy)
+	}
+
+	return enemies
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	game := newTestGame(t)
+
+	roundTrip := func(level randomLevel) bool {
+		if err := game.ImportFromJSON(levelJSON(t, level.data)); err != nil {
+			t.Logf("generated level didn't import: %v", err)
+			return false
+		}
+		exported := game.ExportAsJSON()
+		if expected := levelJSON(t, level.exported()); exported != expected {
+			t.Logf("export doesn't match the imported level:\n%s\n%s", expected, exported)
+			return false
+		}
+
+		if err := game.ImportFromJSON(exported); err != nil {
+			t.Logf("exported level didn't import: %v", err)
+			return false
+		}
+		if reexported := game.ExportAsJSON(); reexported != exported {
+			t.Logf("export changed after a round trip:\n%s\n%s", exported, reexported)
+			return false
+		}
+
+		return true
+	}
+
+	if err := quick.Check(roundTrip, &quick.Config{MaxCount: 200, Rand: rand.New(rand.NewSource(1))}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestImportInvalidLevel(t *testing.T) {
+	invalidBlock := blockData{Position: [3]float32{0, 0, 0}, Dimensions: [3]float32{1, 0, 1}}
+	validBlock := blockData{Position: [3]float32{0, 0, 0}, Dimensions: [3]float32{1, 1, 1}}
+
+	tests := []struct {
+		name    string
+		section string // section of the level at least one of the errors should be for
+		breaks  func(data *gameData)
+	}{
+		{"negative lives", "lives", func(data *gameData) {
+			data.Lives = -1
+		}},
+		{"flat player", "player", func(data *gameData) {
+			data.Player.Dimensions[1] = 0
+		}},
+		{"flat checkpoint", "checkpoints", func(data *gameData) {
+			data.Checkpoints = append(data.Checkpoints, invalidBlock)
+		}},
+		{"flat trigger", "triggers", func(data *gameData) {
+			data.Triggers = append(data.Triggers, triggerData{blockData: invalidBlock})
+		}},
+		{"flat world block", "world", func(data *gameData) {
+			data.World = append(data.World, worldBlockData{blockData: invalidBlock})
+		}},
+		{"unknown material", "world", func(data *gameData) {
+			data.World = append(data.World, worldBlockData{blockData: validBlock, Material: "missing"})
+		}},
+		{"unknown block kind", "world", func(data *gameData) {
+			data.World = append(data.World, worldBlockData{blockData: validBlock, Kind: "quicksand"})
+		}},
+		{"crumbling timed block", "world", func(data *gameData) {
+			data.World = append(data.World, worldBlockData{blockData: validBlock, Crumble: &crumbleData{}, Timer: &timerData{Period: 1}})
+		}},
+		{"motion without a period", "world", func(data *gameData) {
+			data.World = append(data.World, worldBlockData{blockData: validBlock, Motion: &motionData{Type: string(blockMotionOrbit)}})
+		}},
+		{"unknown enemy type", "enemies", func(data *gameData) {
+			data.Enemies = append(data.Enemies, enemyData{blockData: validBlock, Type: "ghost"})
+		}},
+		{"patroller without a path", "enemies", func(data *gameData) {
+			data.Enemies = append(data.Enemies, enemyData{blockData: validBlock, Type: string(enemyKindPatroller)})
+		}},
+		{"unnamed prefab", "prefabs", func(data *gameData) {
+			data.Prefabs = append(data.Prefabs, prefabData{})
+		}},
+		{"duplicate prefab", "prefabs", func(data *gameData) {
+			data.Prefabs = append(data.Prefabs, prefabData{Name: "copy"}, prefabData{Name: "copy"})
+		}},
+		{"prefab with a flat world block", "prefabs[0].world", func(data *gameData) {
+			data.Prefabs = append([]prefabData{{Name: "flat", World: []worldBlockData{{blockData: invalidBlock}}}}, data.Prefabs...)
+		}},
+		{"spawn inside a world block", "player", func(data *gameData) {
+			data.World = append(data.World, worldBlockData{blockData: data.Player})
+		}},
+	}
+
+	game := newTestGame(t)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			breaksLevel := func(level randomLevel, invalid randomLevel) bool {
+				if err := game.ImportFromJSON(levelJSON(t, level.data)); err != nil {
+					t.Logf("generated level didn't import: %v", err)
+					return false
+				}
+				before := game.ExportAsJSON()
+
+				test.breaks(&invalid.data)
+				err := game.ImportFromJSON(levelJSON(t, invalid.data))
+
+				errs, isValidationErrors := err.(LevelValidationErrors)
+				if !isValidationErrors || len(errs) == 0 {
+					t.Logf("expected LevelValidationErrors, got %v", err)
+					return false
+				}
+
+				hasSection := false
+				for _, err := range errs {
+					hasSection = hasSection || err.Section == test.section
+				}
+				if !hasSection {
+					t.Logf("expected an error in %s, got %v", test.section, errs)
+					return false
+				}
+
+				if after := game.ExportAsJSON(); after != before {
+					t.Logf("invalid level changed the game:\n%s\n%s", before, after)
+					return false
+				}
+
+				return true
+			}
+
+			if err := quick.Check(breaksLevel, &quick.Config{MaxCount: 20, Rand: rand.New(rand.NewSource(1))}); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
+
+func TestImportForgetsMissingMaterial(t *testing.T) {
+	game := newTestGame(t)
+	player := blockData{Position: [3]float32{0, 1, 0}, Dimensions: [3]float32{1, 1, 1}}
+
+	withMaterial := gameData{Version: levelVersionCurrent, Player: player, Materials: map[string]materialData{"marble": {Specular: 1}}}
+	if err := game.ImportFromJSON(levelJSON(t, withMaterial)); err != nil {
+		t.Fatal(err)
+	}
+	game.editor.material = "marble"
+
+	// reimporting a level with the material keeps it (the builtin materials are always kept)
+	if err := game.ImportFromJSON(levelJSON(t, withMaterial)); err != nil {
+		t.Fatal(err)
+	}
+	if game.editor.material != "marble" {
+		t.Errorf("expected the editor to keep the level's material, got %q", game.editor.material)
+	}
+
+	if err := game.ImportFromJSON(levelJSON(t, gameData{Version: levelVersionCurrent, Player: player})); err != nil {
+		t.Fatal(err)
+	}
+	if game.editor.material != "" {
+		t.Errorf("expected the editor to forget a material the level doesn't have, got %q", game.editor.material)
+	}
+}
@@ -0,0 +1,39 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// LevelPackEntry a level in a level pack
+type LevelPackEntry struct {
+	Name string `json:"name"`
+	URL  string `json:"url"` // where the level's json is (relative to the level pack)
+}
+
+// LevelPack an ordered list of levels - completing a level moves on to the next one
+type LevelPack struct {
+	Name   string           `json:"name,omitempty"`
+	Levels []LevelPackEntry `json:"levels"`
+}
+
+// ImportLevelPackFromJSON imports a level pack from json data (the levels themselves are loaded separately)
+func ImportLevelPackFromJSON(jsonData string) (*LevelPack, error) {
+	levelPack := new(LevelPack)
+
+	if err := json.Unmarshal([]byte(jsonData), levelPack); err != nil {
+		return nil, err
+	}
+
+	if len(levelPack.Levels) == 0 {
+		return nil, fmt.Errorf("invalid level pack: no levels")
+	}
+
+	for i, level := range levelPack.Levels {
+		if level.URL == "" {
+			return nil, fmt.Errorf("invalid level pack level %d: missing url", i)
+		}
+	}
+
+	return levelPack, nil
+}
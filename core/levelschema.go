@@ -0,0 +1,349 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// version of level files written before the format had a version field
+const levelVersionLegacy = 1
+
+// version of the level format written by ExportAsJSON
+const levelVersionCurrent = 2
+
+// levelMigration upgrades a level in the format of one version to the next (the level is kept as raw json so each migration only
+// needs to know about the fields it changes)
+type levelMigration func(level map[string]json.RawMessage) error
+
+// migrations keyed by the version they upgrade from
+var levelMigrations = map[int]levelMigration{
+	1: migrateLevelFromV1,
+}
+
+// v1 levels ignored the player's dimensions (map1.json has [0,0,0]) - the player has always been a 1x1x1 block. keeps the spawn
+// centered where it was
+func migrateLevelFromV1(level map[string]json.RawMessage) error {
+	rawPlayer, hasPlayer := level["player"]
+	if !hasPlayer {
+		return nil
+	}
+
+	var player blockData
+	if err := json.Unmarshal(rawPlayer, &player); err != nil {
+		return err
+	}
+
+	center := getBlockPosFromData(player)
+	player.Dimensions = [3]float32{1.0, 1.0, 1.0}
+	player.Position = center.Sub(getBlockScaleFromData(player))
+
+	var err error
+	level["player"], err = json.Marshal(&player)
+
+	return err
+}
+
+// migrates level json of any known version up to levelVersionCurrent
+func migrateLevel(jsonData string) ([]byte, error) {
+	var level map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(jsonData), &level); err != nil {
+		return nil, err
+	}
+
+	version := levelVersionLegacy
+	if rawVersion, hasVersion := level["version"]; hasVersion {
+		if err := json.Unmarshal(rawVersion, &version); err != nil {
+			return nil, fmt.Errorf("invalid level version: %v", err)
+		}
+	}
+
+	if version < levelVersionLegacy || version > levelVersionCurrent {
+		return nil, fmt.Errorf("unsupported level version %d (latest supported is %d)", version, levelVersionCurrent)
+	}
+
+	for ; version < levelVersionCurrent; version++ {
+		if err := levelMigrations[version](level); err != nil {
+			return nil, fmt.Errorf("migrating level from version %d: %v", version, err)
+		}
+	}
+
+	level["version"], _ = json.Marshal(version)
+
+	return json.Marshal(level)
+}
+
+// LevelValidationError a problem with a single field of a level
+type LevelValidationError struct {
+	Section string // "player", "lives", "materials", "checkpoints", "triggers", "world", "enemies" or "prefabs" (ex. "prefabs[0].world")
+	Index   int    // index of the block within the section (always 0 for the player, lives & materials)
+	Field   string // name of the field (the material's name for materials, empty for lives)
+	Reason  string
+}
+
+func (err LevelValidationError) Error() string {
+	if err.Section == "player" {
+		return fmt.Sprintf("player.%s: %s", err.Field, err.Reason)
+	}
+
+	if err.Field == "" {
+		return fmt.Sprintf("%s: %s", err.Section, err.Reason)
+	}
+
+	if err.Section == "materials" {
+		return fmt.Sprintf("materials.%s: %s", err.Field, err.Reason)
+	}
+
+	return fmt.Sprintf("%s[%d].%s: %s", err.Section, err.Index, err.Field, err.Reason)
+}
+
+// LevelValidationErrors every problem found with a level
+type LevelValidationErrors []LevelValidationError
+
+func (errs LevelValidationErrors) Error() string {
+	messages := make([]string, 0, len(errs))
+	for _, err := range errs {
+		messages = append(messages, err.Error())
+	}
+
+	return "invalid level: " + strings.Join(messages, "; ")
+}
+
+// checks a (migrated) level for anything the game can't play. returns nil if the level is valid
+func validateLevel(data *gameData) error {
+	var errs LevelValidationErrors
+	addError := func(section string, index int, field string, reason string) {
+		errs = append(errs, LevelValidationError{Section: section, Index: index, Field: field, Reason: reason})
+	}
+
+	validateBlock := func(section string, index int, block blockData) {
+		if !isFiniteVec3(block.Position) {
+			addError(section, index, "position", "must be a finite number")
+		}
+
+		if !isFiniteVec3(block.Dimensions) {
+			addError(section, index, "dimensions", "must be a finite number")
+		} else if block.Dimensions[0] <= 0 || block.Dimensions[1] <= 0 || block.Dimensions[2] <= 0 {
+			addError(section, index, "dimensions", "must be greater than 0")
+		}
+	}
+
+	validateBlock("player", 0, data.Player)
+
+	if data.Lives < 0 {
+		addError("lives", 0, "", "must be at least 0")
+	}
+	for i, checkpointData := range data.Checkpoints {
+		validateBlock("checkpoints", i, checkpointData)
+	}
+	for i, triggerData := range data.Triggers {
+		validateBlock("triggers", i, triggerData.blockData)
+	}
+
+	materialNames := make([]string, 0, len(data.Materials))
+	for name := range data.Materials {
+		materialNames = append(materialNames, name)
+	}
+	sort.Strings(materialNames)
+
+	for _, name := range materialNames {
+		material := data.Materials[name]
+		if !isFinite(material.Ambient) || !isFinite(material.Diffuse) || !isFinite(material.Specular) || !isFinite(material.Shininess) {
+			addError("materials", 0, name, "must be finite numbers")
+		} else if material.Ambient < 0 || material.Diffuse < 0 || material.Specular < 0 || material.Shininess < 0 {
+			addError("materials", 0, name, "must be at least 0")
+		}
+		if material.Color != nil && !isFiniteVec4(*material.Color) {
+			addError("materials", 0, name, "color must be finite numbers")
+		}
+	}
+
+	validateWorldBlock := func(section string, i int, worldBlockData worldBlockData) {
+		validateBlock(section, i, worldBlockData.blockData)
+
+		if worldBlockData.Color != nil && !isFiniteVec4(*worldBlockData.Color) {
+			addError(section, i, "color", "must be finite numbers")
+		}
+		if _, isFound := findMaterial(data.Materials, worldBlockData.Material); !isFound {
+			addError(section, i, "material", fmt.Sprintf("unknown material '%s'", worldBlockData.Material))
+		}
+
+		kind := blockKind(worldBlockData.Kind)
+		if kind != "" && !isValidBlockKind(kind) {
+			addError(section, i, "kind", fmt.Sprintf("unknown block kind '%s'", worldBlockData.Kind))
+		}
+		if worldBlockData.ConveyorVelocity != nil && !isFiniteVec3(*worldBlockData.ConveyorVelocity) {
+			addError(section, i, "conveyorVelocity", "must be finite numbers")
+		}
+
+		if worldBlockData.Motion != nil {
+			if reason := validateMotion(worldBlockData.Motion); reason != "" {
+				addError(section, i, "motion", reason)
+			}
+		}
+
+		if worldBlockData.Crumble != nil && worldBlockData.Timer != nil {
+			addError(section, i, "timer", "a block can't both crumble & be timed")
+		}
+		if crumble := worldBlockData.Crumble; crumble != nil {
+			if !isFinite(crumble.Delay) || !isFinite(crumble.Respawn) || crumble.Delay < 0 || crumble.Respawn < 0 {
+				addError(section, i, "crumble", "delay & respawn must be finite numbers of at least 0")
+			}
+			if mode := crumbleMode(crumble.Mode); mode != "" && !isValidCrumbleMode(mode) {
+				addError(section, i, "crumble", fmt.Sprintf("unknown crumble mode '%s'", crumble.Mode))
+			}
+		}
+		if timer := worldBlockData.Timer; timer != nil {
+			if !isFinite(timer.Period) || timer.Period <= 0 {
+				addError(section, i, "timer", "period must be greater than 0")
+			}
+			if !isFinite(timer.Duty) || timer.Duty < 0 || timer.Duty > 1 || !isFinite(timer.Phase) {
+				addError(section, i, "timer", "duty must be between 0 & 1 (& phase a finite number)")
+			}
+		}
+	}
+
+	validateEnemy := func(section string, i int, enemyData enemyData) {
+		validateBlock(section, i, enemyData.blockData)
+
+		kind := enemyKind(enemyData.Type)
+		if kind != "" && !isValidEnemyKind(kind) {
+			addError(section, i, "type", fmt.Sprintf("unknown enemy type '%s'", enemyData.Type))
+		}
+		if kind == enemyKindPatroller && len(enemyData.Waypoints) == 0 {
+			addError(section, i, "waypoints", "a patroller needs at least 1 waypoint")
+		}
+
+		pathMode := enemyPathMode(enemyData.PathMode)
+		if pathMode != "" && !isValidEnemyPathMode(pathMode) {
+			addError(section, i, "pathMode", fmt.Sprintf("unknown path mode '%s'", enemyData.PathMode))
+		}
+
+		if enemyData.Speed != nil && (!isFinite(*enemyData.Speed) || *enemyData.Speed < 0) {
+			addError(section, i, "speed", "must be a finite number of at least 0")
+		}
+		if enemyData.DetectionRadius != nil && (!isFinite(*enemyData.DetectionRadius) || *enemyData.DetectionRadius < 0) {
+			addError(section, i, "detectionRadius", "must be a finite number of at least 0")
+		}
+
+		for _, waypoint := range enemyData.Waypoints {
+			if !isFiniteVec3(waypoint) {
+				addError(section, i, "waypoints", "must be finite numbers")
+				break
+			}
+		}
+	}
+
+	for i, worldBlockData := range data.World {
+		validateWorldBlock("world", i, worldBlockData)
+	}
+
+	for i, enemyData := range data.Enemies {
+		validateEnemy("enemies", i, enemyData)
+	}
+
+	// prefabs are checked like the level's own world blocks & enemies (ex. as "prefabs[0].world")
+	prefabNames := make(map[string]bool)
+	for i, prefab := range data.Prefabs {
+		if prefab.Name == "" {
+			addError("prefabs", i, "name", "must be set")
+		} else if prefabNames[prefab.Name] {
+			addError("prefabs", i, "name", fmt.Sprintf("duplicate prefab name '%s'", prefab.Name))
+		}
+		prefabNames[prefab.Name] = true
+
+		for j, worldBlockData := range prefab.World {
+			validateWorldBlock(fmt.Sprintf("prefabs[%d].world", i), j, worldBlockData)
+		}
+		for j, enemyData := range prefab.Enemies {
+			validateEnemy(fmt.Sprintf("prefabs[%d].enemies", i), j, enemyData)
+		}
+	}
+
+	// spawning inside something either traps the player or ends the game straight away
+	if len(errs) == 0 {
+		spawn := boundsOfData(data.Player)
+
+		for i, worldBlockData := range data.World {
+			if checkForStaticOnStaticCollision(spawn, boundsOfData(worldBlockData.blockData)) {
+				addError("player", 0, "position", fmt.Sprintf("spawn overlaps world[%d]", i))
+			}
+		}
+
+		for i, enemyData := range data.Enemies {
+			if checkForStaticOnStaticCollision(spawn, boundsOfData(enemyData.blockData)) {
+				addError("player", 0, "position", fmt.Sprintf("spawn overlaps enemies[%d]", i))
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// checks a moving block's motion. returns the reason it's invalid (empty if it's valid)
+func validateMotion(motion *motionData) string {
+	motionType := blockMotionType(motion.Type)
+	if !isValidBlockMotionType(motionType) {
+		return fmt.Sprintf("unknown motion type '%s'", motion.Type)
+	}
+
+	if !isFinite(motion.Period) || !isFinite(motion.Phase) || !isFiniteVec3(motion.Amplitude) || !isFinite(motion.Radius) {
+		return "must be finite numbers"
+	}
+
+	if (motionType == blockMotionOscillate || motionType == blockMotionOrbit) && motion.Period <= 0 {
+		return "period must be greater than 0"
+	}
+
+	if motionType != blockMotionPath {
+		return ""
+	}
+
+	pathMode := enemyPathMode(motion.PathMode)
+	if pathMode != "" && !isValidEnemyPathMode(pathMode) {
+		return fmt.Sprintf("unknown path mode '%s'", motion.PathMode)
+	}
+
+	if len(motion.Keyframes) < 2 {
+		return "a path needs at least 2 keyframes"
+	}
+
+	for i, keyframe := range motion.Keyframes {
+		if !isFinite(keyframe.Time) || !isFiniteVec3(keyframe.Offset) {
+			return "keyframes must be finite numbers"
+		}
+		if i > 0 && keyframe.Time < motion.Keyframes[i-1].Time {
+			return "keyframe times must be in order"
+		}
+	}
+
+	if motion.Keyframes[len(motion.Keyframes)-1].Time <= motion.Keyframes[0].Time {
+		return "a path's last keyframe must be after it's first"
+	}
+
+	return ""
+}
+
+func boundsOfData(data blockData) bounds {
+	return bounds{min: mgl32.Vec3(data.Position), max: mgl32.Vec3(data.Position).Add(mgl32.Vec3(data.Dimensions))}
+}
+
+func isFinite(value float32) bool {
+	return !math.IsNaN(float64(value)) && !math.IsInf(float64(value), 0)
+}
+
+func isFiniteVec3(vec [3]float32) bool {
+	return isFinite(vec[0]) && isFinite(vec[1]) && isFinite(vec[2])
+}
+
+func isFiniteVec4(vec [4]float32) bool {
+	return isFinite(vec[0]) && isFinite(vec[1]) && isFinite(vec[2]) && isFinite(vec[3])
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// name of the material used by blocks that don't name one
+const materialNameDefault = "default"
+
+// materialData how a surface is shaded (as written in the materials table of the level data)
+type materialData struct {
+	Ambient   float32     `json:"ambient"`
+	Diffuse   float32     `json:"diffuse"`
+	Specular  float32     `json:"specular"`
+	Shininess float32     `json:"shininess"`
+	Color     *[4]float32 `json:"color,omitempty"` // default color of blocks using the material
+}
+
+// materials available to every level - a level's materials table can add to (or override) these
+var builtinMaterials = map[string]materialData{
+	materialNameDefault: {Ambient: 0.1, Diffuse: 0.6, Specular: 0.0, Shininess: 20.0},
+	"matte":             {Ambient: 0.2, Diffuse: 0.8, Specular: 0.0, Shininess: 1.0},
+	"shiny":             {Ambient: 0.1, Diffuse: 0.5, Specular: 1.0, Shininess: 80.0},
+	"glow":              {Ambient: 1.0, Diffuse: 0.0, Specular: 0.0, Shininess: 1.0},
+
+	// materials of the block kinds
+	"ice":      {Ambient: 0.2, Diffuse: 0.6, Specular: 1.0, Shininess: 60.0, Color: &[4]float32{0.7, 0.9, 1.0, 1.0}},
+	"bounce":   {Ambient: 0.2, Diffuse: 0.7, Specular: 0.3, Shininess: 30.0, Color: &[4]float32{0.3, 0.9, 0.4, 1.0}},
+	"lava":     {Ambient: 0.9, Diffuse: 0.3, Specular: 0.0, Shininess: 1.0, Color: &[4]float32{1.0, 0.35, 0.05, 1.0}},
+	"spikes":   {Ambient: 0.1, Diffuse: 0.6, Specular: 0.6, Shininess: 40.0, Color: &[4]float32{0.45, 0.45, 0.5, 1.0}},
+	"conveyor": {Ambient: 0.1, Diffuse: 0.7, Specular: 0.2, Shininess: 10.0, Color: &[4]float32{0.9, 0.75, 0.2, 1.0}},
+	"sticky":   {Ambient: 0.2, Diffuse: 0.8, Specular: 0.0, Shininess: 1.0, Color: &[4]float32{0.55, 0.35, 0.6, 1.0}},
+	"goal":     {Ambient: 0.7, Diffuse: 0.4, Specular: 0.8, Shininess: 40.0, Color: &[4]float32{1.0, 1.0, 0.7, 1.0}},
+}
+
+// vector of [Ka, Kd, Ks, shininess] (see Game.material)
+func (material materialData) vec4() mgl32.Vec4 {
+	return mgl32.Vec4{material.Ambient, material.Diffuse, material.Specular, material.Shininess}
+}
+
+// blocks that don't name a material use the default material
+func orDefaultMaterialName(name string) string {
+	if name == "" {
+		return materialNameDefault
+	}
+
+	return name
+}
+
+// finds a material by name in the level's materials table falling back to the builtin materials
+func findMaterial(levelMaterials map[string]materialData, name string) (materialData, bool) {
+	name = orDefaultMaterialName(name)
+
+	if material, isFound := levelMaterials[name]; isFound {
+		return material, true
+	}
+
+	material, isFound := builtinMaterials[name]
+	return material, isFound
+}
+
+// names of every material available to the current level (sorted)
+func (game *Game) materialNames() []string {
+	names := make([]string, 0, len(builtinMaterials)+len(game.materials))
+	for name := range builtinMaterials {
+		names = append(names, name)
+	}
+	for name := range game.materials {
+		if _, isBuiltin := builtinMaterials[name]; !isBuiltin {
+			names = append(names, name)
+		}
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,223 @@
+package core
+
+import (
+	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// blockMotionType how a moving world block moves (as named in the level data)
+type blockMotionType string
+
+const (
+	// back & forth through the authored position (sine wave)
+	blockMotionOscillate blockMotionType = "oscillate"
+	// along keyframes (offsets from the authored position at given times)
+	blockMotionPath blockMotionType = "path"
+	// in a circle on the x/z plane (passing through the authored position)
+	blockMotionOrbit blockMotionType = "orbit"
+)
+
+// motionData how a world block moves (as written in the level data). times are in seconds, offsets are relative to the block's
+// authored position
+type motionData struct {
+	Type      string         `json:"type"`
+	Period    float32        `json:"period,omitempty"`    // oscillate & orbit - seconds for a full cycle
+	Phase     float32        `json:"phase,omitempty"`     // oscillate & orbit - fraction [0, 1) of a cycle to start at
+	Amplitude [3]float32     `json:"amplitude,omitempty"` // oscillate - furthest offset from the authored position
+	Radius    float32        `json:"radius,omitempty"`    // orbit
+	Keyframes []keyframeData `json:"keyframes,omitempty"` // path
+	PathMode  string         `json:"pathMode,omitempty"`  // path - "loop" (restart from the first keyframe) or "pingpong"
+}
+
+type keyframeData struct {
+	Time   float32    `json:"time"`
+	Offset [3]float32 `json:"offset"`
+}
+
+// blockMotion offset of a moving world block from it's authored position over time
+type blockMotion interface {
+	// offset at the given time (ms since play started)
+	offsetAt(time float32) mgl32.Vec3
+}
+
+func newBlockMotion(data *motionData) blockMotion {
+	if data == nil {
+		return nil
+	}
+
+	switch blockMotionType(data.Type) {
+	case blockMotionOscillate:
+		return oscillateMotion{period: data.Period * 1000, phase: data.Phase, amplitude: mgl32.Vec3(data.Amplitude)}
+	case blockMotionPath:
+		return pathMotion{keyframes: data.Keyframes, isPingPong: enemyPathMode(data.PathMode) == enemyPathModePingPong}
+	case blockMotionOrbit:
+		return orbitMotion{period: data.Period * 1000, phase: data.Phase, radius: data.Radius}
+	}
+
+	return nil
+}
+
+func isValidBlockMotionType(motionType blockMotionType) bool {
+	return motionType == blockMotionOscillate || motionType == blockMotionPath || motionType == blockMotionOrbit
+}
+
+// fraction [0, 1) of the way through a cycle of the given period (ms)
+func cycleFraction(time, period, phase float32) float32 {
+	fraction := time/period + phase
+	return fraction - float32(math.Floor(float64(fraction)))
+}
+
+type oscillateMotion struct {
+	period    float32 // ms
+	phase     float32
+	amplitude mgl32.Vec3
+}
+
+func (motion oscillateMotion) offsetAt(time float32) mgl32.Vec3 {
+	angle := 2 * math.Pi * float64(cycleFraction(time, motion.period, motion.phase))
+	return motion.amplitude.Mul(float32(math.Sin(angle)))
+}
+
+type orbitMotion struct {
+	period float32 // ms
+	phase  float32
+	radius float32
+}
+
+func (motion orbitMotion) offsetAt(time float32) mgl32.Vec3 {
+	angle := 2 * math.Pi * float64(cycleFraction(time, motion.period, motion.phase))
+	return mgl32.Vec3{motion.radius * (float32(math.Cos(angle)) - 1), 0, motion.radius * float32(math.Sin(angle))}
+}
+
+type pathMotion struct {
+	keyframes  []keyframeData // sorted by time
+	isPingPong bool
+}
+
+func (motion pathMotion) offsetAt(time float32) mgl32.Vec3 {
+	first := motion.keyframes[0]
+	last := motion.keyframes[len(motion.keyframes)-1]
+
+	// time along the path (seconds)
+	duration := last.Time - first.Time
+	t := time / 1000
+	if motion.isPingPong {
+		t = float32(math.Mod(float64(t), float64(2*duration)))
+		if t > duration {
+			t = 2*duration - t
+		}
+	} else {
+		t = float32(math.Mod(float64(t), float64(duration)))
+	}
+	t += first.Time
+
+	for i := 1; i < len(motion.keyframes); i++ {
+		from := motion.keyframes[i-1]
+		to := motion.keyframes[i]
+		if t > to.Time {
+			continue
+		}
+
+		if to.Time == from.Time {
+			return mgl32.Vec3(to.Offset)
+		}
+
+		return lerpVec3(mgl32.Vec3(from.Offset), mgl32.Vec3(to.Offset), (t-from.Time)/(to.Time-from.Time))
+	}
+
+	return mgl32.Vec3(last.Offset)
+}
+
+// moves the moving world blocks to where they are at the current time - carrying anything standing on them & pushing anything
+// in their way. in edit mode the blocks stay at their authored positions
+func (game *Game) updateMovingBlocks(dt float32) {
+	if game.IsEditModeEnabled {
+		game.time = 0
+	} else {
+		game.time += dt
+	}
+
+	for _, worldBlock := range game.worldBlocks {
+		if worldBlock.motion == nil {
+			continue
+		}
+
+		worldBlock.prevPos = worldBlock.pos
+		if game.IsEditModeEnabled {
+			worldBlock.pos = worldBlock.origin
+		} else {
+			worldBlock.pos = worldBlock.origin.Add(worldBlock.motion.offsetAt(game.time))
+		}
+
+		dPos := worldBlock.pos.Sub(worldBlock.prevPos)
+		worldBlock.vel = dPos.Mul(1000 / dt)
+		if dPos.Len() == 0 {
+			continue
+		}
+
+		// passable blocks aren't indexed (& don't carry or push anything)
+		if worldBlock.isPassable {
+			continue
+		}
+
+		game.worldIndex.update(worldBlock)
+
+		if game.IsEditModeEnabled {
+			continue
+		}
+
+		player := game.player
+		game.moveWithBlock(worldBlock, dPos, &player.pos, player.scale, player.contacts)
+		for _, enemy := range game.enemies {
+			if !enemy.behavior.isStationary() {
+				game.moveWithBlock(worldBlock, dPos, &enemy.pos, enemy.scale, enemy.contacts)
+			}
+		}
+	}
+}
+
+// carries an entity (centered at pos) along with a moving block it's standing on, or pushes it out of the way if the block moved
+// into it. the entity is still blocked by the rest of the world
+func (game *Game) moveWithBlock(worldBlock *worldBlock, dPos mgl32.Vec3, pos *mgl32.Vec3, scale mgl32.Vec3, contacts contactState) {
+	body := bounds{min: pos.Sub(scale), max: pos.Add(scale)}
+
+	var move mgl32.Vec3
+	if contacts.ground == worldBlock {
+		move = dPos
+	} else if checkForStaticOnStaticCollision(body, worldBlock) {
+		move = pushOutOf(body, boundsOf(worldBlock), dPos)
+	} else {
+		return
+	}
+
+	others := withoutWorldBlock(game.worldIndex.query(body.expand(move)), worldBlock)
+	move, _ = resolveDynamicOnStaticCollisions(move, body, others)
+
+	*pos = pos.Add(move)
+}
+
+// smallest move (along one of the axes the block moved on) that gets the body out of the block
+func pushOutOf(body, block bounds, dPos mgl32.Vec3) mgl32.Vec3 {
+	var push mgl32.Vec3
+	bestDistance := float32(math.Inf(1))
+
+	for axis := 0; axis < 3; axis++ {
+		var distance float32
+		if dPos[axis] > 0 {
+			distance = block.max[axis] - body.min[axis]
+		} else if dPos[axis] < 0 {
+			distance = block.min[axis] - body.max[axis]
+		} else {
+			continue
+		}
+
+		if f32Abs(distance) < bestDistance {
+			bestDistance = f32Abs(distance)
+			push = mgl32.Vec3{}
+			push[axis] = distance
+		}
+	}
+
+	return push
+}
@@ -0,0 +1,348 @@
+package core
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// size of a navigation cell on the x/z plane
+const navCellSize float32 = 1
+
+// height of the space an enemy needs above a surface to walk on it
+const navAgentHeight float32 = 1
+
+// half the width of the space an enemy needs to walk through (a bit smaller than an enemy so narrow ledges still count)
+const navAgentHalfWidth float32 = 0.25
+
+// furthest an enemy will drop down from one surface to another
+const navMaxDrop float32 = 8
+
+// furthest (in cells) an enemy will jump across a gap
+const navMaxGapCells = 2
+
+// highest an enemy can climb onto a surface (a bit under the jump height so the jump actually clears the ledge)
+var navMaxClimb = maxJumpHeight() * 0.9
+
+// height reached by a jump - integrated step by step the same way the player & enemies move
+func maxJumpHeight() float32 {
+	var height float32
+	for vel := jumpVelocity - gravityAcceleration; vel > 0; vel -= gravityAcceleration {
+		height += vel * fixedTimeStep / 1000
+	}
+
+	return height
+}
+
+type navColumn [2]int32
+
+// navNode a walkable spot on top of a world block
+type navNode struct {
+	column navColumn
+	pos    mgl32.Vec3 // center of the spot (y is the top of the world block)
+	edges  []navEdge
+}
+
+// navEdge a way to move from one walkable spot to another
+type navEdge struct {
+	to   *navNode
+	cost float32
+	jump bool // whether the move needs a jump (climbing onto a higher block or crossing a gap)
+}
+
+// navGraph walkable spots (& the moves between them) derived from the tops of the world blocks
+type navGraph struct {
+	nodes   []*navNode
+	columns map[navColumn][]*navNode // nodes in each x/z column sorted by height
+}
+
+func toNavColumn(pos mgl32.Vec3) navColumn {
+	return navColumn{
+		int32(math.Floor(float64(pos.X() / navCellSize))),
+		int32(math.Floor(float64(pos.Z() / navCellSize))),
+	}
+}
+
+// range of cells (inclusive) who's centers lie in [min, max] - falls back to the cell containing the middle for small ranges
+func navCellRange(min, max float32) (int32, int32) {
+	cellMin := int32(math.Ceil(float64(min/navCellSize - 0.5)))
+	cellMax := int32(math.Floor(float64(max/navCellSize - 0.5)))
+
+	if cellMin > cellMax {
+		cellMin = int32(math.Floor(float64((min + max) / 2 / navCellSize)))
+		cellMax = cellMin
+	}
+
+	return cellMin, cellMax
+}
+
+func newNavGraph(worldBlocks []*worldBlock, worldIndex *spatialHash) *navGraph {
+	graph := new(navGraph)
+	graph.columns = make(map[navColumn][]*navNode)
+
+	// moving, crumbling & timed blocks aren't walkable surfaces (they'd only be where they are when the graph was built)
+	for _, worldBlock := range worldBlocks {
+		if worldBlock.isStatic() {
+			graph.addNodesOnTopOf(worldBlock, worldIndex)
+		}
+	}
+
+	for _, column := range graph.columns {
+		sort.Slice(column, func(i, j int) bool { return column[i].pos.Y() < column[j].pos.Y() })
+	}
+
+	for _, node := range graph.nodes {
+		graph.addEdgesFrom(node, worldIndex)
+	}
+
+	return graph
+}
+
+func (graph *navGraph) addNodesOnTopOf(worldBlock *worldBlock, worldIndex *spatialHash) {
+	cellMinX, cellMaxX := navCellRange(worldBlock.right(), worldBlock.left())
+	cellMinZ, cellMaxZ := navCellRange(worldBlock.back(), worldBlock.front())
+
+	for x := cellMinX; x <= cellMaxX; x++ {
+		for z := cellMinZ; z <= cellMaxZ; z++ {
+			pos := mgl32.Vec3{
+				f32LimitBetween((float32(x)+0.5)*navCellSize, worldBlock.right(), worldBlock.left()),
+				worldBlock.top(),
+				f32LimitBetween((float32(z)+0.5)*navCellSize, worldBlock.back(), worldBlock.front()),
+			}
+			column := navColumn{x, z}
+
+			if graph.hasNodeAt(column, pos.Y()) || !isClear(pos, pos, pos.Y(), worldIndex) {
+				continue
+			}
+
+			node := &navNode{column: column, pos: pos}
+			graph.nodes = append(graph.nodes, node)
+			graph.columns[column] = append(graph.columns[column], node)
+		}
+	}
+}
+
+func (graph *navGraph) hasNodeAt(column navColumn, height float32) bool {
+	for _, node := range graph.columns[column] {
+		if f32Abs(node.pos.Y()-height) < collisionEpsilon {
+			return true
+		}
+	}
+
+	return false
+}
+
+// highest node in the column at or under the given height (nil if there is none)
+func (graph *navGraph) highestNodeUnder(column navColumn, height float32) *navNode {
+	var highest *navNode
+	for _, node := range graph.columns[column] {
+		if node.pos.Y() <= height {
+			highest = node
+		}
+	}
+
+	return highest
+}
+
+func (graph *navGraph) addEdgesFrom(node *navNode, worldIndex *spatialHash) {
+	for dx := int32(-navMaxGapCells); dx <= navMaxGapCells; dx++ {
+		for dz := int32(-navMaxGapCells); dz <= navMaxGapCells; dz++ {
+			distance := maxInt32(absInt32(dx), absInt32(dz))
+			if distance == 0 {
+				continue
+			}
+
+			isGap := distance > 1
+			if isGap && (dx%distance != 0 || dz%distance != 0) {
+				continue // only jump gaps in straight lines
+			}
+
+			for _, neighbor := range graph.columns[navColumn{node.column[0] + dx, node.column[1] + dz}] {
+				climb := neighbor.pos.Y() - node.pos.Y()
+				if climb > navMaxClimb || -1*climb > navMaxDrop {
+					continue
+				}
+
+				// gaps are only jumped if there's nothing to walk on in between
+				if isGap && graph.hasWalkableBetween(node, neighbor, distance) {
+					continue
+				}
+
+				if !isClear(node.pos, neighbor.pos, f32Max(node.pos.Y(), neighbor.pos.Y()), worldIndex) {
+					continue
+				}
+
+				edge := navEdge{to: neighbor, jump: isGap || climb > 0.1}
+				edge.cost = mgl32.Vec2{neighbor.pos.X() - node.pos.X(), neighbor.pos.Z() - node.pos.Z()}.Len() + f32Max(0, climb)
+				if edge.jump {
+					edge.cost += navCellSize
+				}
+
+				node.edges = append(node.edges, edge)
+			}
+		}
+	}
+}
+
+func (graph *navGraph) hasWalkableBetween(from, to *navNode, distance int32) bool {
+	stepX := (to.column[0] - from.column[0]) / distance
+	stepZ := (to.column[1] - from.column[1]) / distance
+	minHeight := f32Min(from.pos.Y(), to.pos.Y()) - navAgentHeight
+
+	for i := int32(1); i < distance; i++ {
+		for _, node := range graph.columns[navColumn{from.column[0] + i*stepX, from.column[1] + i*stepZ}] {
+			if node.pos.Y() >= minHeight {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// checks there's room for an enemy to move from one position to the other at the given height
+func isClear(from, to mgl32.Vec3, height float32, worldIndex *spatialHash) bool {
+	space := bounds{
+		min: mgl32.Vec3{f32Min(from.X(), to.X()) - navAgentHalfWidth, height + collisionEpsilon, f32Min(from.Z(), to.Z()) - navAgentHalfWidth},
+		max: mgl32.Vec3{f32Max(from.X(), to.X()) + navAgentHalfWidth, height + navAgentHeight, f32Max(from.Z(), to.Z()) + navAgentHalfWidth},
+	}
+
+	for _, worldBlock := range worldIndex.query(space) {
+		if worldBlock.isStatic() && checkForStaticOnStaticCollision(space, worldBlock) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// the node an entity with the given bottom center is standing on (or is above)
+func (graph *navGraph) nodeAt(pos mgl32.Vec3) *navNode {
+	if node := graph.highestNodeUnder(toNavColumn(pos), pos.Y()+collisionEpsilon); node != nil {
+		return node
+	}
+
+	// standing on an edge of a block that doesn't cover it's cell's center - check the surrounding cells
+	column := toNavColumn(pos)
+	var closest *navNode
+	var closestDistance float32
+	for dx := int32(-1); dx <= 1; dx++ {
+		for dz := int32(-1); dz <= 1; dz++ {
+			node := graph.highestNodeUnder(navColumn{column[0] + dx, column[1] + dz}, pos.Y()+collisionEpsilon)
+			if node == nil {
+				continue
+			}
+
+			distance := node.pos.Sub(pos).Len()
+			if closest == nil || distance < closestDistance {
+				closest = node
+				closestDistance = distance
+			}
+		}
+	}
+
+	return closest
+}
+
+// A* search from one node to another. returns the edges to follow (nil if there's no path)
+func (graph *navGraph) findPath(from, to *navNode) []navEdge {
+	if from == nil || to == nil {
+		return nil
+	}
+
+	heuristic := func(node *navNode) float32 {
+		return mgl32.Vec2{to.pos.X() - node.pos.X(), to.pos.Z() - node.pos.Z()}.Len()
+	}
+
+	costs := map[*navNode]float32{from: 0}
+	cameFrom := make(map[*navNode]navEdge)
+	cameFromNode := make(map[*navNode]*navNode)
+	closed := make(map[*navNode]bool)
+
+	open := &navQueue{}
+	heap.Push(open, navQueueItem{node: from, priority: heuristic(from)})
+
+	for open.Len() > 0 {
+		current := heap.Pop(open).(navQueueItem).node
+		if current == to {
+			break
+		}
+
+		if closed[current] {
+			continue
+		}
+		closed[current] = true
+
+		for _, edge := range current.edges {
+			cost := costs[current] + edge.cost
+			if existingCost, isVisited := costs[edge.to]; isVisited && existingCost <= cost {
+				continue
+			}
+
+			costs[edge.to] = cost
+			cameFrom[edge.to] = edge
+			cameFromNode[edge.to] = current
+			heap.Push(open, navQueueItem{node: edge.to, priority: cost + heuristic(edge.to)})
+		}
+	}
+
+	if _, isReached := costs[to]; !isReached {
+		return nil
+	}
+
+	path := make([]navEdge, 0)
+	for node := to; node != from; node = cameFromNode[node] {
+		path = append(path, cameFrom[node])
+	}
+
+	// path was built backwards
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path
+}
+
+type navQueueItem struct {
+	node     *navNode
+	priority float32
+}
+
+// navQueue a min heap of nodes by priority (implements heap.Interface)
+type navQueue []navQueueItem
+
+func (queue navQueue) Len() int {
+	return len(queue)
+}
+
+func (queue navQueue) Less(i, j int) bool {
+	return queue[i].priority < queue[j].priority
+}
+
+func (queue navQueue) Swap(i, j int) {
+	queue[i], queue[j] = queue[j], queue[i]
+}
+
+func (queue *navQueue) Push(item interface{}) {
+	*queue = append(*queue, item.(navQueueItem))
+}
+
+func (queue *navQueue) Pop() interface{} {
+	old := *queue
+	item := old[len(old)-1]
+	*queue = old[:len(old)-1]
+
+	return item
+}
+
+// navigation returns the navigation graph - rebuilding it if the world has changed since it was last built
+func (game *Game) navigation() *navGraph {
+	if game.navGraph == nil || game.isNavGraphDirty {
+		game.navGraph = newNavGraph(game.worldBlocks, game.worldIndex)
+		game.isNavGraphDirty = false
+	}
+
+	return game.navGraph
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// size of the blocks placed with PlaceBlockAt when there's no snap grid
+const pickPlaceBlockSize float32 = 1
+
+// PickResult the world block or enemy under a point of the viewport
+type PickResult struct {
+	Kind   string     // "world" or "enemy"
+	Index  int        // index of the world block or enemy in the level
+	Point  [3]float32 // where the ray from the camera hit it
+	Normal [3]float32 // normal of the face that was hit
+}
+
+// ray from the camera through a point of the viewport (in pixels from the top left - like a mouse event's offsetX/offsetY)
+func (game *Game) viewportRay(x, y float32) (mgl32.Vec3, mgl32.Vec3) {
+	width := float32(game.gl.GetViewportWidth())
+	height := float32(game.gl.GetViewportHeight())
+
+	// normalized device coordinates have y pointing up
+	ndcX := 2*x/width - 1
+	ndcY := 1 - 2*y/height
+
+	inverse := game.projMatrix.Mul4(game.camera.getViewMatrix()).Inv()
+	near := mgl32.TransformCoordinate(mgl32.Vec3{ndcX, ndcY, -1}, inverse)
+	far := mgl32.TransformCoordinate(mgl32.Vec3{ndcX, ndcY, 1}, inverse)
+
+	return near, far.Sub(near).Normalize()
+}
+
+// the nearest world block or enemy the ray from the camera through the point of the viewport hits - returns where it was hit &
+// the normal of the face that was hit
+func (game *Game) pick(x, y float32) (editorTarget, mgl32.Vec3, mgl32.Vec3, bool) {
+	origin, dir := game.viewportRay(x, y)
+
+	var nearest editorTarget
+	var nearestTime float32
+	var nearestNormal mgl32.Vec3
+
+	check := func(target editorTarget) {
+		t, normal, isHit := intersectRayWithBounds(origin, dir, boundsOf(target))
+		if isHit && (nearest == nil || t < nearestTime) {
+			nearest, nearestTime, nearestNormal = target, t, normal
+		}
+	}
+
+	for _, worldBlock := range game.worldBlocks {
+		check(worldBlock)
+	}
+	for _, enemy := range game.enemies {
+		check(enemy)
+	}
+
+	if nearest == nil {
+		return nil, mgl32.Vec3{}, mgl32.Vec3{}, false
+	}
+
+	return nearest, origin.Add(dir.Mul(nearestTime)), nearestNormal, true
+}
+
+// Pick returns the nearest world block or enemy under the point of the viewport (in pixels from the top left - like a mouse
+// event's offsetX/offsetY). returns false if there's nothing there
+func (game *Game) Pick(x, y float32) (PickResult, bool) {
+	target, point, normal, isHit := game.pick(x, y)
+	if !isHit {
+		return PickResult{}, false
+	}
+
+	result := PickResult{Point: point, Normal: normal}
+	for i, worldBlock := range game.worldBlocks {
+		if target == editorTarget(worldBlock) {
+			result.Kind, result.Index = "world", i
+		}
+	}
+	for i, enemy := range game.enemies {
+		if target == editorTarget(enemy) {
+			result.Kind, result.Index = "enemy", i
+		}
+	}
+
+	return result, true
+}
+
+// SelectAt selects the world block or enemy under the point of the viewport in edit mode (see Pick) - the movement keys then
+// move it like one selected with GameInputEditModeSelect. returns false (& deselects) if there's nothing there
+func (game *Game) SelectAt(x, y float32) bool {
+	if !game.IsEditModeEnabled {
+		return false
+	}
+
+	target, _, _, isHit := game.pick(x, y)
+	if !isHit {
+		game.editor.selectOnly(nil)
+		return false
+	}
+
+	game.editor.selectOnly(target)
+
+	return true
+}
+
+// ToggleSelectionAt adds the world block or enemy under the point of the viewport to the selection in edit mode (or removes it
+// if it's already selected). returns false if there's nothing there
+func (game *Game) ToggleSelectionAt(x, y float32) bool {
+	if !game.IsEditModeEnabled {
+		return false
+	}
+
+	target, _, _, isHit := game.pick(x, y)
+	if !isHit {
+		return false
+	}
+
+	game.editor.toggleSelected(target)
+
+	return true
+}
+
+// PlaceBlockAt adds a world block (a snap grid cell in size) against the face under the point of the viewport in edit mode (see
+// Pick). the block is given the kind, material & motion the editor gives new blocks. returns false if there's nothing there (or
+// the block would overlap the player's spawn)
+func (game *Game) PlaceBlockAt(x, y float32) bool {
+	if !game.IsEditModeEnabled {
+		return false
+	}
+
+	editor := game.editor
+	target, point, normal, isHit := game.pick(x, y)
+	if !isHit {
+		return false
+	}
+
+	size := pickPlaceBlockSize
+	if editor.gridSize > 0 {
+		size = editor.gridSize
+	}
+
+	// centered on the point hit (snapped to the grid) & just outside the face that was hit
+	hitBounds := boundsOf(target)
+	var data blockData
+	data.Dimensions = [3]float32{size, size, size}
+	for axis := 0; axis < 3; axis++ {
+		switch {
+		case normal[axis] > 0:
+			data.Position[axis] = hitBounds.max[axis]
+		case normal[axis] < 0:
+			data.Position[axis] = hitBounds.min[axis] - size
+		default:
+			data.Position[axis] = snapToGrid(point[axis]-size/2, editor.gridSize)
+		}
+	}
+
+	if game.overlapsSpawn(data) {
+		return false
+	}
+
+	worldBlock := new(worldBlock)
+	worldBlock.data.blockData = data
+	worldBlock.data.Material = editor.material
+	worldBlock.data.Kind = string(editor.blockKind)
+	worldBlock.data.Motion = editorMotionPresets[editor.motionPreset].newMotionData()
+	worldBlock.place(getBlockPosFromData(data))
+	worldBlock.scale = getBlockScaleFromData(data)
+	worldBlock.applyData(game)
+
+	editor.history.execute(game, &addWorldBlockCommand{worldBlock: worldBlock})
+
+	return true
+}
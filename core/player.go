@@ -12,11 +12,19 @@ var playerColor = mgl32.Vec4{0.3, 0.5, 1.0, 1.0}
 
 type player struct {
 	pos            mgl32.Vec3
+	prevPos        mgl32.Vec3 // position at the start of the last simulation step
 	scale          mgl32.Vec3
 	vel            mgl32.Vec3
+	contacts       contactState // faces touched during the last simulation step
+	momentum       mgl32.Vec3   // world space velocity carried over from the (moving) block last stood on - kept until landing
 	jumpAnimTStart float32
 }
 
+// position interpolated between the last two simulation steps
+func (player *player) interpolatedPos(t float32) mgl32.Vec3 {
+	return lerpVec3(player.prevPos, player.pos, t)
+}
+
 func (player *player) velocity() mgl32.Vec3 {
 	return player.vel
 }
@@ -46,21 +54,27 @@ func (player *player) back() float32 {
 }
 
 func (player *player) update(game *Game, dt float32, inputs map[GameInput]bool) {
+	// the block being stood on changes how the player moves
+	surface := player.contacts.groundProperties()
+	acceleration := playerAcceleration * surface.traction
+	friction := dampening * surface.traction
+	maxSpeed := maxVelocity * surface.speedLimit
+
 	var dvx, dvy, dvz float32
 	if inputs[GameInputPlayerMoveLeft] {
-		dvx = playerAcceleration
+		dvx = acceleration
 	} else if inputs[GameInputPlayerMoveRight] {
-		dvx = -1 * playerAcceleration
+		dvx = -1 * acceleration
 	} else if player.vel.X() != 0 {
-		dvx = -1 * player.vel.X() / f32Abs(player.vel.X()) * dampening
+		dvx = -1 * player.vel.X() / f32Abs(player.vel.X()) * f32Min(friction, f32Abs(player.vel.X()))
 	}
 
 	if inputs[GameInputPlayerMoveForward] {
-		dvz = playerAcceleration
+		dvz = acceleration
 	} else if inputs[GameInputPlayerMoveBack] {
-		dvz = -1 * playerAcceleration
+		dvz = -1 * acceleration
 	} else if player.vel.Z() != 0 {
-		dvz = -1 * player.vel.Z() / f32Abs(player.vel.Z()) * dampening
+		dvz = -1 * player.vel.Z() / f32Abs(player.vel.Z()) * f32Min(friction, f32Abs(player.vel.Z()))
 	}
 
 	if !game.IsEditModeEnabled {
@@ -77,33 +91,49 @@ func (player *player) update(game *Game, dt float32, inputs map[GameInput]bool)
 	}
 
 	player.vel = player.vel.Add(mgl32.Vec3{dvx, dvy, dvz})
-	player.vel[0] = f32LimitBetween(player.vel[0], -1*maxVelocity, maxVelocity)
+	player.vel[0] = f32LimitBetween(player.vel[0], -1*maxSpeed, maxSpeed)
 	player.vel[1] = f32Max(player.vel[1], -1*terminalVelocity) // terminal velocity
-	player.vel[2] = f32LimitBetween(player.vel[2], -1*maxVelocity, maxVelocity)
+	player.vel[2] = f32LimitBetween(player.vel[2], -1*maxSpeed, maxSpeed)
 
 	if game.IsEditModeEnabled {
 		player.vel[1] = f32LimitBetween(player.vel[1], -1*maxVelocity, maxVelocity) // editor mode just uses regular max velocity
 	}
 
 	dPos := game.player.vel.Mul(dt / 1000)
-	dPosOriginal := dPos
 
 	camera := game.camera.(*arcballCamera)
 	dPos = mgl32.HomogRotate3DY(mgl32.DegToRad(180 + camera.yaw)).Mul4x1(dPos.Vec4(1.0)).Vec3()
 
+	// conveyors carry the player along in world space (regardless of which way the camera faces)
+	dPos = dPos.Add(player.contacts.groundVelocity().Mul(dt / 1000))
+
+	// moving blocks carry the player while they stand on it (see updateMovingBlocks) - once off it they keep it's momentum
+	if player.contacts.grounded {
+		player.momentum = mgl32.Vec3{player.contacts.ground.vel.X(), 0, player.contacts.ground.vel.Z()}
+	} else {
+		dPos = dPos.Add(player.momentum.Mul(dt / 1000))
+	}
+
 	if !game.IsEditModeEnabled {
-		for _, worldBlock := range game.worldBlocks {
-			if checkForDynamicOnStaticCollision(dPos, player, worldBlock) {
-				dPos = processDynamicOnStaticCollisionDetails(dt, dPos, game.player, worldBlock)
-			}
-		}
+		var contacts []collisionContact
+		dPos, contacts = resolveDynamicOnStaticCollisions(dPos, player, game.worldIndex.query(boundsOf(player).expand(dPos)))
+		player.contacts = newContactState(contacts, player.contacts)
+		player.vel = player.contacts.clampVelocity(player.vel)
+	} else {
+		player.contacts = contactState{}
+		player.momentum = mgl32.Vec3{}
 	}
 
 	player.pos = player.pos.Add(dPos)
 
-	playerCanJump := (dPosOriginal.Y() < 0 && dPos.Y() == 0)
-	if inputs[GameInputPlayerJump] && playerCanJump {
-		player.vel[1] = 20 * gravityAcceleration
+	if inputs[GameInputPlayerJump] && player.contacts.grounded {
+		player.vel[1] = jumpVelocity*player.contacts.groundProperties().jumpScale + f32Max(0, player.contacts.ground.vel.Y())
+		game.stats.Jumps++
+	}
+
+	// bounce pads launch the player back up on landing
+	if player.contacts.landed {
+		player.vel[1] = f32Max(player.vel[1], player.contacts.groundProperties().bounceVelocity)
 	}
 
 	if game.IsEditModeEnabled {
@@ -112,8 +142,10 @@ func (player *player) update(game *Game, dt float32, inputs map[GameInput]bool)
 }
 
 func (player *player) render(game *Game, viewMatrix mgl32.Mat4) error {
+	pos := player.interpolatedPos(game.interpolation)
+
 	scaleMatrix := mgl32.Scale3D(player.scale.X(), player.scale.Y(), player.scale.Z())
-	translateMatrix := mgl32.Translate3D(player.pos.X(), player.pos.Y(), player.pos.Z())
+	translateMatrix := mgl32.Translate3D(pos.X(), pos.Y(), pos.Z())
 
 	modelMatrix := mgl32.Ident4().Mul4(translateMatrix).Mul4(scaleMatrix)
 
@@ -122,7 +154,7 @@ func (player *player) render(game *Game, viewMatrix mgl32.Mat4) error {
 	game.normalMatrix = game.modelViewMatrix.Inv().Transpose()
 	game.color = playerColor
 	game.material = mgl32.Vec4{0.4, 0.7, 1.0, 50.0}
-	game.lightPos = viewMatrix.Mul4x1(game.player.pos.Add(mgl32.Vec3{0.0, 2.0, 0.0}).Vec4(1.0)).Vec3()
+	game.lightPos = viewMatrix.Mul4x1(pos.Add(mgl32.Vec3{0.0, 2.0, 0.0}).Vec4(1.0)).Vec3()
 
 	if err := game.gl.RenderTriangles(game.blockMesh, game.phongShader); err != nil {
 		return err
@@ -0,0 +1,284 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// prefabData a group of world blocks & enemies that can be placed in the level any number of times (positions & waypoints are
+// relative to the group's right bottom back corner)
+type prefabData struct {
+	Name    string           `json:"name"`
+	World   []worldBlockData `json:"world,omitempty"`
+	Enemies []enemyData      `json:"enemies,omitempty"`
+}
+
+// prefab of the world blocks & enemies - returns it & the (right bottom back) corner of the group it's relative to
+func newPrefabData(name string, targets []editorTarget) (prefabData, mgl32.Vec3) {
+	prefab := prefabData{Name: name}
+
+	origin := mgl32.Vec3(targets[0].editBounds().Position)
+	for _, target := range targets {
+		position := target.editBounds().Position
+		for axis := 0; axis < 3; axis++ {
+			origin[axis] = f32Min(origin[axis], position[axis])
+		}
+	}
+
+	for _, target := range targets {
+		switch target := target.(type) {
+		case *worldBlock:
+			prefab.World = append(prefab.World, target.data)
+		case *enemy:
+			prefab.Enemies = append(prefab.Enemies, newEnemyData(target))
+		}
+	}
+
+	return prefab.clone().translate(origin.Mul(-1)), origin
+}
+
+// deep copy (so the copy doesn't share colors, motions etc. with what it was made from)
+func (prefab prefabData) clone() prefabData {
+	var clone prefabData
+
+	data, _ := json.Marshal(&prefab)
+	json.Unmarshal(data, &clone)
+
+	return clone
+}
+
+// moves everything in the prefab by the offset (the prefab is modified in place)
+func (prefab prefabData) translate(offset mgl32.Vec3) prefabData {
+	for i := range prefab.World {
+		prefab.World[i].Position = mgl32.Vec3(prefab.World[i].Position).Add(offset)
+	}
+
+	for i := range prefab.Enemies {
+		enemy := &prefab.Enemies[i]
+		enemy.Position = mgl32.Vec3(enemy.Position).Add(offset)
+		for j := range enemy.Waypoints {
+			enemy.Waypoints[j] = mgl32.Vec3(enemy.Waypoints[j]).Add(offset)
+		}
+	}
+
+	return prefab
+}
+
+// new world blocks & enemies for a copy of the prefab with it's corner at the origin
+func (prefab prefabData) instantiate(game *Game, origin mgl32.Vec3) ([]*worldBlock, []*enemy) {
+	placed := prefab.clone().translate(origin)
+
+	worldBlocks := make([]*worldBlock, 0, len(placed.World))
+	for _, worldBlockData := range placed.World {
+		worldBlocks = append(worldBlocks, newWorldBlockFromData(game, worldBlockData))
+	}
+
+	enemies := make([]*enemy, 0, len(placed.Enemies))
+	for _, enemyData := range placed.Enemies {
+		enemies = append(enemies, newEnemyFromData(enemyData))
+	}
+
+	return worldBlocks, enemies
+}
+
+// editorClipboard what was last copied in the editor
+type editorClipboard struct {
+	prefab prefabData
+	offset mgl32.Vec3 // from the player's right bottom back corner to the group's when it was copied
+}
+
+// placeCommand adds a group of world blocks & enemies (pasted, duplicated or placed from a prefab)
+type placeCommand struct {
+	description string
+	worldBlocks []*worldBlock
+	enemies     []*enemy
+}
+
+func (command *placeCommand) do(game *Game) {
+	for _, worldBlock := range command.worldBlocks {
+		game.addWorldBlock(worldBlock)
+	}
+
+	for _, enemy := range command.enemies {
+		game.insertEnemy(enemy, len(game.enemies))
+	}
+}
+
+func (command *placeCommand) undo(game *Game) {
+	for _, worldBlock := range command.worldBlocks {
+		game.removeWorldBlock(worldBlock)
+	}
+
+	for _, enemy := range command.enemies {
+		game.removeEnemy(enemy)
+	}
+}
+
+func (command *placeCommand) describe() string {
+	return command.description
+}
+
+// prefabsCommand changes the level's prefabs
+type prefabsCommand struct {
+	description string
+	before      []prefabData
+	after       []prefabData
+}
+
+func (command *prefabsCommand) do(game *Game) {
+	game.prefabs = command.after
+}
+
+func (command *prefabsCommand) undo(game *Game) {
+	game.prefabs = command.before
+}
+
+func (command *prefabsCommand) describe() string {
+	return command.description
+}
+
+// places a copy of the prefab with it's corner at the origin & selects it. nothing is placed if any of it would overlap the
+// player's spawn
+func (editor *gameEditor) placePrefab(game *Game, prefab prefabData, origin mgl32.Vec3, description string) error {
+	worldBlocks, enemies := prefab.instantiate(game, origin)
+	for _, worldBlock := range worldBlocks {
+		if game.overlapsSpawn(worldBlock.data.blockData) {
+			return fmt.Errorf("can't %s over the player's spawn", description)
+		}
+	}
+	for _, enemy := range enemies {
+		if game.overlapsSpawn(enemy.data) {
+			return fmt.Errorf("can't %s over the player's spawn", description)
+		}
+	}
+
+	editor.history.execute(game, &placeCommand{description: description, worldBlocks: worldBlocks, enemies: enemies})
+
+	editor.selection = nil
+	for _, worldBlock := range worldBlocks {
+		editor.selection = append(editor.selection, worldBlock)
+	}
+	for _, enemy := range enemies {
+		editor.selection = append(editor.selection, enemy)
+	}
+	editor.onSelectionChange()
+
+	return nil
+}
+
+func (editor *gameEditor) copySelection(game *Game) {
+	if len(editor.selection) == 0 {
+		fmt.Printf("nothing selected to copy\n")
+		return
+	}
+
+	prefab, origin := newPrefabData("", editor.selection)
+	editor.clipboard = &editorClipboard{prefab: prefab, offset: origin.Sub(getBlockPosition(game.player))}
+
+	fmt.Printf("copied %s\n", describeTargets(editor.selection))
+}
+
+// pastes the copy so it's in the same place relative to the player as when it was copied (snapped to the grid)
+func (editor *gameEditor) paste(game *Game) {
+	if editor.clipboard == nil {
+		fmt.Printf("nothing copied to paste\n")
+		return
+	}
+
+	origin := snapVec3ToGrid(getBlockPosition(game.player).Add(editor.clipboard.offset), editor.gridSize)
+	err := editor.placePrefab(game, editor.clipboard.prefab, origin, fmt.Sprintf(
+		"paste %d world blocks & %d enemies", len(editor.clipboard.prefab.World), len(editor.clipboard.prefab.Enemies),
+	))
+	if err != nil {
+		fmt.Printf("%v\n", err)
+	}
+}
+
+// copies the selection in place (the copy is selected so it can be moved off the original)
+func (editor *gameEditor) duplicateSelection(game *Game) {
+	if len(editor.selection) == 0 {
+		fmt.Printf("nothing selected to duplicate\n")
+		return
+	}
+
+	description := fmt.Sprintf("duplicate %s", describeTargets(editor.selection))
+	prefab, origin := newPrefabData("", editor.selection)
+	if err := editor.placePrefab(game, prefab, origin, description); err != nil {
+		fmt.Printf("%v\n", err)
+	}
+}
+
+func (game *Game) findPrefab(name string) (prefabData, bool) {
+	for _, prefab := range game.prefabs {
+		if prefab.Name == name {
+			return prefab, true
+		}
+	}
+
+	return prefabData{}, false
+}
+
+// PrefabNames the names of the level's prefabs (in the order they were saved)
+func (game *Game) PrefabNames() []string {
+	names := make([]string, 0, len(game.prefabs))
+	for _, prefab := range game.prefabs {
+		names = append(names, prefab.Name)
+	}
+
+	return names
+}
+
+// SavePrefab saves the editor's selection as a prefab (replacing any prefab with the same name). prefabs are saved with the
+// level (see ExportAsJSON)
+func (game *Game) SavePrefab(name string) error {
+	editor := game.editor
+	if name == "" {
+		return fmt.Errorf("a prefab needs a name")
+	}
+	if len(editor.selection) == 0 {
+		return fmt.Errorf("nothing selected to save as a prefab")
+	}
+
+	prefab, _ := newPrefabData(name, editor.selection)
+
+	after := make([]prefabData, 0, len(game.prefabs)+1)
+	isReplaced := false
+	for _, existing := range game.prefabs {
+		if existing.Name == name {
+			existing, isReplaced = prefab, true
+		}
+		after = append(after, existing)
+	}
+	if !isReplaced {
+		after = append(after, prefab)
+	}
+
+	editor.history.execute(game, &prefabsCommand{
+		description: fmt.Sprintf("save prefab (%s)", name),
+		before:      game.prefabs,
+		after:       after,
+	})
+
+	return nil
+}
+
+// PlacePrefab places a copy of the named prefab in edit mode with it's right bottom back corner at the player's (snapped to the
+// editor's grid). the copy is selected so it can be moved into place. returns an error (& places nothing) if the copy would
+// overlap the player's spawn
+func (game *Game) PlacePrefab(name string) error {
+	if !game.IsEditModeEnabled {
+		return fmt.Errorf("prefabs can only be placed in edit mode")
+	}
+
+	prefab, isFound := game.findPrefab(name)
+	if !isFound {
+		return fmt.Errorf("unknown prefab '%s'", name)
+	}
+
+	editor := game.editor
+	origin := snapVec3ToGrid(getBlockPosition(game.player), editor.gridSize)
+
+	return editor.placePrefab(game, prefab, origin, fmt.Sprintf("place prefab (%s)", name))
+}
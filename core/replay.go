@@ -0,0 +1,209 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// replayFrame a run of consecutive simulation steps that all had the same inputs
+type replayFrame struct {
+	Inputs []GameInput `json:"inputs"`
+	Steps  int         `json:"steps"`
+}
+
+// Replay a recorded run - the level it was played on, the editor's state at the start & the inputs fed into every simulation
+// step
+type Replay struct {
+	Level  string        `json:"level"`
+	Editor editorState   `json:"editor"`
+	Frames []replayFrame `json:"frames"`
+}
+
+// Steps the total # of simulation steps in the replay
+func (replay *Replay) Steps() int {
+	steps := 0
+	for _, frame := range replay.Frames {
+		steps += frame.Steps
+	}
+
+	return steps
+}
+
+func (replay *Replay) record(inputs map[GameInput]bool) {
+	activeInputs := make([]GameInput, 0, len(inputs))
+	for input, isActive := range inputs {
+		if isActive {
+			activeInputs = append(activeInputs, input)
+		}
+	}
+	sort.Slice(activeInputs, func(i, j int) bool { return activeInputs[i] < activeInputs[j] })
+
+	if len(replay.Frames) > 0 {
+		lastFrame := &replay.Frames[len(replay.Frames)-1]
+		if isSameInputs(lastFrame.Inputs, activeInputs) {
+			lastFrame.Steps++
+			return
+		}
+	}
+
+	replay.Frames = append(replay.Frames, replayFrame{Inputs: activeInputs, Steps: 1})
+}
+
+func isSameInputs(inputs1, inputs2 []GameInput) bool {
+	if len(inputs1) != len(inputs2) {
+		return false
+	}
+
+	for i := range inputs1 {
+		if inputs1[i] != inputs2[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ExportAsJSON exports the replay into json data
+func (replay *Replay) ExportAsJSON() string {
+	json, _ := json.Marshal(replay)
+
+	return string(json)
+}
+
+// ImportReplayFromJSON imports a replay from json data
+func ImportReplayFromJSON(jsonData string) (*Replay, error) {
+	replay := new(Replay)
+
+	if err := json.Unmarshal([]byte(jsonData), replay); err != nil {
+		return nil, err
+	}
+
+	for i, frame := range replay.Frames {
+		if frame.Steps <= 0 {
+			return nil, fmt.Errorf("invalid replay frame %d: steps must be positive", i)
+		}
+	}
+
+	if err := replay.Editor.validate(); err != nil {
+		return nil, fmt.Errorf("invalid replay editor: %v", err)
+	}
+
+	return replay, nil
+}
+
+// replayPlayback tracks how far through a replay we are
+type replayPlayback struct {
+	replay     *Replay
+	frameIndex int
+	frameStep  int
+}
+
+func (playback *replayPlayback) isDone() bool {
+	return playback.frameIndex >= len(playback.replay.Frames)
+}
+
+// inputs for the next simulation step
+func (playback *replayPlayback) next() map[GameInput]bool {
+	inputs := make(map[GameInput]bool)
+	if playback.isDone() {
+		return inputs
+	}
+
+	frame := playback.replay.Frames[playback.frameIndex]
+	for _, input := range frame.Inputs {
+		inputs[input] = true
+	}
+
+	playback.frameStep++
+	if playback.frameStep >= frame.Steps {
+		playback.frameIndex++
+		playback.frameStep = 0
+	}
+
+	return inputs
+}
+
+// StartRecording restarts the current level (keeping edit mode & the editor's settings, clipboard & selection) & starts
+// recording the inputs of every simulation step
+func (game *Game) StartRecording() error {
+	if err := game.restart(game.levelJSON, game.saveEditorState()); err != nil {
+		return err
+	}
+
+	game.recording = new(Replay)
+	game.recording.Level = game.levelJSON
+	game.recording.Editor = game.saveEditorState()
+
+	return nil
+}
+
+// StopRecording stops recording & returns the recorded replay (nil if we weren't recording)
+func (game *Game) StopRecording() *Replay {
+	replay := game.recording
+	game.recording = nil
+
+	return replay
+}
+
+// Recording returns the replay currently being recorded (nil if we aren't recording)
+func (game *Game) Recording() *Replay {
+	return game.recording
+}
+
+// StartReplay loads the replay's level (with the editor as it was when recording started) & plays back it's inputs as the game is updated (live inputs are ignored until it's done)
+func (game *Game) StartReplay(replay *Replay) error {
+	game.recording = nil
+
+	if err := game.restart(replay.Level, replay.Editor); err != nil {
+		return err
+	}
+
+	game.playback = &replayPlayback{replay: replay}
+
+	return nil
+}
+
+// IsReplaying whether a replay is currently being played back
+func (game *Game) IsReplaying() bool {
+	return game.playback != nil
+}
+
+// RunReplay plays back the whole replay immediately (stops early if the game is over or the level is completed)
+func (game *Game) RunReplay(replay *Replay) error {
+	if err := game.StartReplay(replay); err != nil {
+		return err
+	}
+
+	for game.IsReplaying() && !game.hasEnded() {
+		game.Update(fixedTimeStep, nil)
+	}
+
+	return nil
+}
+
+// restart re-imports the given level & resets all other state so simulations starting from it are deterministic. the editor
+// is put in the given state (with an empty history)
+func (game *Game) restart(levelJSON string, editor editorState) error {
+	if err := game.ImportFromJSON(levelJSON); err != nil {
+		return err
+	}
+
+	game.player.vel = mgl32.Vec3{}
+	game.player.momentum = mgl32.Vec3{}
+	game.player.contacts = contactState{}
+	game.camera.reset()
+	game.camera.follow(game.player.pos)
+	game.loadEditorState(editor)
+
+	game.accumulator = 0
+	game.interpolation = 0
+	game.pendingInputs = make(map[GameInput]bool)
+	game.playback = nil
+	game.IsGameOver = false
+	game.IsLevelComplete = false
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package core_test
+
+import (
+	"testing"
+
+	"github.com/cpoonolly/blockgame/core"
+	"github.com/cpoonolly/blockgame/headless"
+)
+
+// a bit of everything that moves or changes over time - moving, crumbling & timed blocks, enemies, a checkpoint & a trigger
+const replayLevel = `{
+	"version": 2,
+	"player": {"position": [0, 1, 0], "dimensions": [1, 1, 1]},
+	"checkpoints": [{"position": [0, 1, -6], "dimensions": [1, 2, 1]}],
+	"triggers": [{"position": [2, 1, -2], "dimensions": [2, 2, 2], "message": "hello"}],
+	"world": [
+		{"position": [-5, 0, -10], "dimensions": [10, 1, 12]},
+		{"position": [-3, 1, -8], "dimensions": [2, 0.5, 2], "motion": {"type": "oscillate", "period": 3, "amplitude": [2, 0, 0]}},
+		{"position": [2, 1, -8], "dimensions": [2, 0.5, 2], "crumble": {"delay": 0.5, "mode": "fall", "respawn": 2}},
+		{"position": [-1, 2, -4], "dimensions": [2, 0.5, 2], "timer": {"period": 2, "duty": 0.5}}
+	],
+	"enemies": [
+		{"position": [4, 1, 1], "dimensions": [0.5, 0.5, 0.5], "type": "patroller", "waypoints": [[4, 1.25, -8], [-4, 1.25, -8]]},
+		{"position": [-4, 1, 1], "dimensions": [0.5, 0.5, 0.5], "type": "chaser", "speed": 1}
+	]
+}`
+
+// frame times a browser might give us (so the live run doesn't line up with the simulation steps)
+var replayFrameTimes = []float32{16.7, 7.1, 33.3, 12.5, 16.6, 25}
+
+// what's held down on each frame of the live run
+func replayInputs(frame int) map[core.GameInput]bool {
+	inputs := make(map[core.GameInput]bool)
+	switch {
+	case frame < 60:
+		inputs[core.GameInputPlayerMoveForward] = true
+		inputs[core.GameInputPlayerJump] = frame%20 == 0
+	case frame < 120:
+		inputs[core.GameInputPlayerMoveLeft] = true
+		inputs[core.GameInputCameraRotateLeft] = true
+	case frame < 200:
+		inputs[core.GameInputPlayerMoveBack] = true
+		inputs[core.GameInputPlayerMoveRight] = frame%3 == 0
+		inputs[core.GameInputPlayerJump] = frame%45 == 0
+	}
+
+	return inputs
+}
+
+func newReplayGame(t *testing.T) *core.Game {
+	gl, err := headless.New(64, 48)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	game, err := core.NewGame(gl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return game
+}
+
+// plays the live run (rendering every frame like the host does) & returns it's recording
+func recordReplay(t *testing.T, game *core.Game, frames int) *core.Replay {
+	if err := game.StartRecording(); err != nil {
+		t.Fatal(err)
+	}
+
+	for frame := 0; frame < frames; frame++ {
+		game.Update(replayFrameTimes[frame%len(replayFrameTimes)], replayInputs(frame))
+		game.Render()
+	}
+
+	return game.StopRecording()
+}
+
+// plays the replay back on a new game (after a trip through json, like a replay attached to a bug report)
+func playReplay(t *testing.T, replay *core.Replay) *core.Game {
+	imported, err := core.ImportReplayFromJSON(replay.ExportAsJSON())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	game := newReplayGame(t)
+	if err := game.RunReplay(imported); err != nil {
+		t.Fatal(err)
+	}
+
+	return game
+}
+
+func assertSameRun(t *testing.T, live *core.Game, replayed *core.Game) {
+	if live.PlayerPos() != replayed.PlayerPos() {
+		t.Errorf("expected the replay to end with the player at %v, got %v", live.PlayerPos(), replayed.PlayerPos())
+	}
+	if live.Stats() != replayed.Stats() {
+		t.Errorf("expected the replay to end with stats %+v, got %+v", live.Stats(), replayed.Stats())
+	}
+	if live.Lives() != replayed.Lives() || live.IsGameOver != replayed.IsGameOver || live.IsLevelComplete != replayed.IsLevelComplete {
+		t.Errorf("expected the replay to end with %d lives (game over: %v, complete: %v), got %d (game over: %v, complete: %v)",
+			live.Lives(), live.IsGameOver, live.IsLevelComplete, replayed.Lives(), replayed.IsGameOver, replayed.IsLevelComplete)
+	}
+	if live.ExportAsJSON() != replayed.ExportAsJSON() {
+		t.Errorf("expected the replay to end with the same level")
+	}
+}
+
+func TestReplayIsDeterministic(t *testing.T) {
+	live := newReplayGame(t)
+	if err := live.ImportFromJSON(replayLevel); err != nil {
+		t.Fatal(err)
+	}
+
+	start := live.PlayerPos()
+	replay := recordReplay(t, live, 240)
+	if live.PlayerPos() == start {
+		t.Fatalf("expected the live run to move the player")
+	}
+
+	assertSameRun(t, live, playReplay(t, replay))
+}
+
+func TestReplayStartsInEditMode(t *testing.T) {
+	live := newReplayGame(t)
+	if err := live.ImportFromJSON(replayLevel); err != nil {
+		t.Fatal(err)
+	}
+
+	// recording starts with the editor as it is
+	live.Update(1000.0/60.0, map[core.GameInput]bool{core.GameInputEditModeToggle: true})
+	live.Update(1000.0/60.0, map[core.GameInput]bool{core.GameInputEditModeCycleGridSize: true})
+	replay := recordReplay(t, live, 60)
+	if !live.IsEditModeEnabled || live.EditorGridSize() == 0 {
+		t.Fatalf("expected recording to keep edit mode & the snap grid")
+	}
+
+	replayed := playReplay(t, replay)
+	if !replayed.IsEditModeEnabled || replayed.EditorGridSize() != live.EditorGridSize() {
+		t.Errorf("expected the replay to start in edit mode with a %v snap grid, got edit mode: %v grid: %v",
+			live.EditorGridSize(), replayed.IsEditModeEnabled, replayed.EditorGridSize())
+	}
+	assertSameRun(t, live, replayed)
+}
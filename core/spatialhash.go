@@ -0,0 +1,153 @@
+package core
+
+import (
+	"math"
+)
+
+// size of a spatial hash cell - roughly the size of a typical platform
+const spatialHashCellSize float32 = 4
+
+type spatialHashCell [3]int32
+
+// spatialHash a broadphase index of world blocks bucketed by the grid cells their bounds overlap
+type spatialHash struct {
+	cellSize    float32
+	cells       map[spatialHashCell][]*worldBlock
+	blocks      []*worldBlock // every indexed block (in insertion order)
+	blockBounds map[*worldBlock]bounds
+}
+
+func newSpatialHash(cellSize float32) *spatialHash {
+	hash := new(spatialHash)
+	hash.cellSize = cellSize
+	hash.cells = make(map[spatialHashCell][]*worldBlock)
+	hash.blocks = make([]*worldBlock, 0, 100)
+	hash.blockBounds = make(map[*worldBlock]bounds)
+
+	return hash
+}
+
+func (hash *spatialHash) toCell(pos float32) int32 {
+	return int32(math.Floor(float64(pos / hash.cellSize)))
+}
+
+// range of cells (inclusive) overlapped by the given bounds
+func (hash *spatialHash) cellRange(area bounds) (spatialHashCell, spatialHashCell) {
+	minCell := spatialHashCell{hash.toCell(area.min.X()), hash.toCell(area.min.Y()), hash.toCell(area.min.Z())}
+	maxCell := spatialHashCell{hash.toCell(area.max.X()), hash.toCell(area.max.Y()), hash.toCell(area.max.Z())}
+
+	return minCell, maxCell
+}
+
+func cellCount(minCell, maxCell spatialHashCell) int64 {
+	return int64(maxCell[0]-minCell[0]+1) * int64(maxCell[1]-minCell[1]+1) * int64(maxCell[2]-minCell[2]+1)
+}
+
+func (hash *spatialHash) insert(block *worldBlock) {
+	area := boundsOf(block)
+	hash.blocks = append(hash.blocks, block)
+	hash.blockBounds[block] = area
+
+	minCell, maxCell := hash.cellRange(area)
+	for x := minCell[0]; x <= maxCell[0]; x++ {
+		for y := minCell[1]; y <= maxCell[1]; y++ {
+			for z := minCell[2]; z <= maxCell[2]; z++ {
+				cell := spatialHashCell{x, y, z}
+				hash.cells[cell] = append(hash.cells[cell], block)
+			}
+		}
+	}
+}
+
+func (hash *spatialHash) remove(block *worldBlock) {
+	area, isIndexed := hash.blockBounds[block]
+	if !isIndexed {
+		return
+	}
+
+	delete(hash.blockBounds, block)
+	hash.blocks = withoutWorldBlock(hash.blocks, block)
+
+	minCell, maxCell := hash.cellRange(area)
+	for x := minCell[0]; x <= maxCell[0]; x++ {
+		for y := minCell[1]; y <= maxCell[1]; y++ {
+			for z := minCell[2]; z <= maxCell[2]; z++ {
+				cell := spatialHashCell{x, y, z}
+
+				blocks := withoutWorldBlock(hash.cells[cell], block)
+				if len(blocks) == 0 {
+					delete(hash.cells, cell)
+				} else {
+					hash.cells[cell] = blocks
+				}
+			}
+		}
+	}
+}
+
+// re-indexes a block after it's position or scale has changed
+func (hash *spatialHash) update(block *worldBlock) {
+	if area, isIndexed := hash.blockBounds[block]; isIndexed && area == boundsOf(block) {
+		return
+	}
+
+	hash.remove(block)
+	hash.insert(block)
+}
+
+// returns all blocks overlapping or touching the given area
+func (hash *spatialHash) query(area collidable) []*worldBlock {
+	results := make([]*worldBlock, 0, 8)
+
+	minCell, maxCell := hash.cellRange(boundsOf(area))
+
+	// for huge areas it's cheaper to just check every block
+	if cellCount(minCell, maxCell) > int64(len(hash.blocks)) {
+		for _, block := range hash.blocks {
+			if checkForTouching(area, hash.blockBounds[block]) {
+				results = append(results, block)
+			}
+		}
+
+		return results
+	}
+
+	for x := minCell[0]; x <= maxCell[0]; x++ {
+		for y := minCell[1]; y <= maxCell[1]; y++ {
+			for z := minCell[2]; z <= maxCell[2]; z++ {
+				for _, block := range hash.cells[spatialHashCell{x, y, z}] {
+					if containsWorldBlock(results, block) || !checkForTouching(area, hash.blockBounds[block]) {
+						continue
+					}
+
+					results = append(results, block)
+				}
+			}
+		}
+	}
+
+	return results
+}
+
+func containsWorldBlock(blocks []*worldBlock, block *worldBlock) bool {
+	for _, existing := range blocks {
+		if existing == block {
+			return true
+		}
+	}
+
+	return false
+}
+
+// removes a block from the slice (in place, preserving order)
+func withoutWorldBlock(blocks []*worldBlock, block *worldBlock) []*worldBlock {
+	for i, existing := range blocks {
+		if existing == block {
+			copy(blocks[i:], blocks[i+1:])
+			blocks[len(blocks)-1] = nil
+			return blocks[:len(blocks)-1]
+		}
+	}
+
+	return blocks
+}
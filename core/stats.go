@@ -0,0 +1,29 @@
+package core
+
+// GameStats statistics of the current run (since the level was imported or edit mode was last left)
+type GameStats struct {
+	MaxHeight   float32 // highest the bottom of the player has been
+	ElapsedTime float32 // ms of play
+	Jumps       int
+	Deaths      int     // lives lost (including the last one)
+	Distance    float32 // total distance the player has moved (not counting respawns)
+}
+
+// Stats statistics of the current run
+func (game *Game) Stats() GameStats {
+	return game.stats
+}
+
+// starts a new run
+func (game *Game) resetStats() {
+	game.stats = GameStats{MaxHeight: game.player.bottom()}
+}
+
+// records the player's movement over the last simulation step
+func (game *Game) updateStats(dt float32) {
+	player := game.player
+
+	game.stats.ElapsedTime += dt
+	game.stats.MaxHeight = f32Max(game.stats.MaxHeight, player.bottom())
+	game.stats.Distance += player.pos.Sub(player.prevPos).Len()
+}
@@ -0,0 +1,180 @@
+package core
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// crumbleMode what a crumbling block does once it gives way (as named in the level data)
+type crumbleMode string
+
+const (
+	// disappears on the spot
+	crumbleModeVanish crumbleMode = "vanish"
+	// drops out of the level
+	crumbleModeFall crumbleMode = "fall"
+)
+
+func isValidCrumbleMode(mode crumbleMode) bool {
+	return mode == crumbleModeVanish || mode == crumbleModeFall
+}
+
+// crumbleData a block that gives way a while after the player first stands on it (as written in the level data)
+type crumbleData struct {
+	Delay   float32 `json:"delay"`             // seconds from first being stood on until the block gives way
+	Mode    string  `json:"mode,omitempty"`    // "vanish" (the default) or "fall"
+	Respawn float32 `json:"respawn,omitempty"` // seconds from giving way until the block is back (0 to never come back)
+}
+
+// timerData a block that switches between solid & not solid on the game clock (as written in the level data)
+type timerData struct {
+	Period float32 `json:"period"`          // seconds for a full solid/not solid cycle
+	Duty   float32 `json:"duty,omitempty"`  // fraction of the cycle the block is solid (0.5 if not set)
+	Phase  float32 `json:"phase,omitempty"` // fraction [0, 1) of a cycle to start at
+}
+
+// fraction of a timed block's solid time (at the end) it spends fading out as a warning
+const timerWarningFraction float32 = 0.3
+
+// color crumbling blocks fade to as they're about to give way
+var crumbleColor = mgl32.Vec4{0.35, 0.2, 0.1, 1.0}
+
+// how much of it's color a timed block keeps while it isn't solid
+const timerPassableBrightness float32 = 0.25
+
+// crumblePhase where a crumbling block is in it's life cycle
+type crumblePhase int
+
+const (
+	crumbleIntact crumblePhase = iota
+	crumbleCrumbling
+	crumbleGone
+)
+
+// crumbleState the state of a crumbling block
+type crumbleState struct {
+	phase        crumblePhase
+	time         float32 // ms spent in the current phase
+	fallDistance float32 // how far a falling block has dropped from where it was
+	prevFall     float32 // fallDistance at the start of the last simulation step
+	fallVel      float32
+}
+
+// updates crumbling & timed world blocks - in edit mode every block is put back to solid
+func (game *Game) updateTimedBlocks(dt float32) {
+	for _, worldBlock := range game.worldBlocks {
+		crumble := worldBlock.data.Crumble
+		timer := worldBlock.data.Timer
+		if crumble == nil && timer == nil {
+			continue
+		}
+
+		worldBlock.crumble.prevFall = worldBlock.crumble.fallDistance
+
+		if game.IsEditModeEnabled {
+			worldBlock.crumble = crumbleState{}
+			worldBlock.color = worldBlock.baseColor
+			game.setWorldBlockPassable(worldBlock, false)
+			continue
+		}
+
+		if crumble != nil {
+			game.updateCrumblingBlock(worldBlock, crumble, dt)
+		} else {
+			game.updateTimerBlock(worldBlock, timer)
+		}
+	}
+}
+
+func (game *Game) updateCrumblingBlock(worldBlock *worldBlock, crumble *crumbleData, dt float32) {
+	state := &worldBlock.crumble
+	state.time += dt
+
+	switch state.phase {
+	case crumbleIntact:
+		if game.player.contacts.ground == worldBlock {
+			state.phase = crumbleCrumbling
+			state.time = 0
+		}
+	case crumbleCrumbling:
+		delay := crumble.Delay * 1000
+		if state.time < delay {
+			worldBlock.color = lerpVec4(worldBlock.baseColor, crumbleColor, state.time/delay)
+			break
+		}
+
+		state.phase = crumbleGone
+		state.time = 0
+		game.setWorldBlockPassable(worldBlock, true)
+	case crumbleGone:
+		if crumbleMode(crumble.Mode) == crumbleModeFall {
+			state.fallVel = f32Min(state.fallVel+gravityAcceleration, terminalVelocity)
+			state.fallDistance += state.fallVel * dt / 1000
+		}
+
+		if crumble.Respawn <= 0 || state.time < crumble.Respawn*1000 {
+			break
+		}
+
+		// wait for the spot to clear so nothing gets stuck inside the block
+		if game.isOccupied(worldBlock) {
+			break
+		}
+
+		*state = crumbleState{}
+		worldBlock.color = worldBlock.baseColor
+		game.setWorldBlockPassable(worldBlock, false)
+	}
+}
+
+func (game *Game) updateTimerBlock(worldBlock *worldBlock, timer *timerData) {
+	duty := timer.Duty
+	if duty == 0 {
+		duty = 0.5
+	}
+
+	fraction := cycleFraction(game.time, timer.Period*1000, timer.Phase)
+	isSolid := fraction < duty
+	game.setWorldBlockPassable(worldBlock, !isSolid)
+
+	passableColor := worldBlock.baseColor.Mul(timerPassableBrightness)
+	passableColor[3] = worldBlock.baseColor[3]
+
+	// fade towards the passable color as a warning before the block stops being solid
+	warningStart := duty * (1 - timerWarningFraction)
+	if !isSolid {
+		worldBlock.color = passableColor
+	} else if fraction > warningStart {
+		worldBlock.color = lerpVec4(worldBlock.baseColor, passableColor, (fraction-warningStart)/(duty-warningStart))
+	} else {
+		worldBlock.color = worldBlock.baseColor
+	}
+}
+
+// makes a block passable (or solid again) - only solid blocks are in the broadphase index so nothing collides with passable ones
+func (game *Game) setWorldBlockPassable(worldBlock *worldBlock, isPassable bool) {
+	if worldBlock.isPassable == isPassable {
+		return
+	}
+
+	worldBlock.isPassable = isPassable
+	if isPassable {
+		game.worldIndex.remove(worldBlock)
+	} else {
+		game.worldIndex.insert(worldBlock)
+	}
+}
+
+// whether the player or an enemy is inside the block
+func (game *Game) isOccupied(worldBlock *worldBlock) bool {
+	if checkForStaticOnStaticCollision(game.player, worldBlock) {
+		return true
+	}
+
+	for _, enemy := range game.enemies {
+		if checkForStaticOnStaticCollision(enemy, worldBlock) {
+			return true
+		}
+	}
+
+	return false
+}
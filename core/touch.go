@@ -0,0 +1,70 @@
+package core
+
+// height the player has fallen out of the world below
+const fallLimit float32 = -10
+
+// publishes a touch event for every enemy & world block the player is touching (& a death event if they've fallen out of the
+// world). stops once the player dies or the level ends so a single step can't cost more than one life
+func (game *Game) updateTouches() {
+	if game.player.pos.Y() < fallLimit {
+		game.publish(GameEvent{Type: GameEventDeath, Trigger: -1, Block: -1, Enemy: -1, Cause: "fell"})
+		return
+	}
+
+	deaths := game.stats.Deaths
+	isDone := func() bool {
+		return game.stats.Deaths != deaths || game.hasEnded()
+	}
+
+	for i, enemy := range game.enemies {
+		if !checkForStaticOnStaticCollision(game.player, enemy) {
+			continue
+		}
+
+		game.publish(GameEvent{Type: GameEventTouch, Trigger: -1, Block: -1, Enemy: i})
+		if isDone() {
+			return
+		}
+	}
+
+	for _, worldBlock := range game.player.contacts.blocks {
+		game.publish(GameEvent{
+			Type:       GameEventTouch,
+			Trigger:    -1,
+			Block:      game.worldBlockIndex(worldBlock),
+			Enemy:      -1,
+			worldBlock: worldBlock,
+		})
+		if isDone() {
+			return
+		}
+	}
+}
+
+// costs the player a life when they touch an enemy
+func (game *Game) onTouchEnemy(event GameEvent) {
+	if event.Enemy < 0 {
+		return
+	}
+
+	game.publish(GameEvent{Type: GameEventDeath, Trigger: -1, Block: -1, Enemy: event.Enemy, Cause: "enemy"})
+}
+
+// costs the player a life when they touch a deadly block
+func (game *Game) onTouchDeadly(event GameEvent) {
+	if event.worldBlock == nil || !blockKinds[event.worldBlock.kind].isDeadly {
+		return
+	}
+
+	game.publish(GameEvent{Type: GameEventDeath, Trigger: -1, Block: event.Block, Enemy: -1, Cause: "block"})
+}
+
+// completes the level when the player touches a goal
+func (game *Game) onTouchGoal(event GameEvent) {
+	if event.worldBlock == nil || !blockKinds[event.worldBlock.kind].isGoal {
+		return
+	}
+
+	game.IsLevelComplete = true
+	game.publish(GameEvent{Type: GameEventLevelComplete, Trigger: -1, Block: event.Block, Enemy: -1})
+}
@@ -0,0 +1,138 @@
+package core
+
+import (
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// color trigger volumes are outlined with in edit mode
+var triggerColor = mgl32.Vec4{0.7, 0.3, 0.9, 1.0}
+
+// triggerData an invisible volume that fires events as the player & enemies enter/leave it (as written in the level data)
+type triggerData struct {
+	blockData
+	Name    string `json:"name,omitempty"`    // for the host to tell triggers apart
+	Message string `json:"message,omitempty"` // shown to the player on entering (see GameEventMessage)
+}
+
+// trigger a volume that fires enter/stay/exit events for the player & enemies overlapping it
+type trigger struct {
+	pos        mgl32.Vec3
+	scale      mgl32.Vec3
+	data       triggerData         // the trigger as authored in the level
+	index      int                 // index of the trigger in the level (-1 for a checkpoint's volume)
+	checkpoint int                 // index of the checkpoint the trigger is the volume of (-1 for the level's triggers)
+	occupants  map[collidable]bool // what was overlapping the trigger as of the last simulation step
+}
+
+func newTrigger(data triggerData, index int, checkpoint int) *trigger {
+	trigger := new(trigger)
+	trigger.data = data
+	trigger.pos = getBlockPosFromData(data.blockData)
+	trigger.scale = getBlockScaleFromData(data.blockData)
+	trigger.index = index
+	trigger.checkpoint = checkpoint
+	trigger.occupants = make(map[collidable]bool)
+
+	return trigger
+}
+
+func (trigger *trigger) left() float32 {
+	return trigger.pos.X() + trigger.scale.X()
+}
+
+func (trigger *trigger) right() float32 {
+	return trigger.pos.X() - trigger.scale.X()
+}
+
+func (trigger *trigger) top() float32 {
+	return trigger.pos.Y() + trigger.scale.Y()
+}
+
+func (trigger *trigger) bottom() float32 {
+	return trigger.pos.Y() - trigger.scale.Y()
+}
+
+func (trigger *trigger) front() float32 {
+	return trigger.pos.Z() + trigger.scale.Z()
+}
+
+func (trigger *trigger) back() float32 {
+	return trigger.pos.Z() - trigger.scale.Z()
+}
+
+// fires trigger events for the player & every enemy (in that order). in edit mode triggers forget what's in them (without
+// firing exit events)
+func (game *Game) updateTriggers() {
+	for _, trigger := range game.triggers {
+		if game.IsEditModeEnabled {
+			trigger.occupants = make(map[collidable]bool)
+			continue
+		}
+
+		game.updateTriggerOccupant(trigger, game.player, -1)
+		for i, enemy := range game.enemies {
+			game.updateTriggerOccupant(trigger, enemy, i)
+		}
+	}
+}
+
+// fires an enter, stay or exit event if the entity (enemyIndex of -1 for the player) is or was overlapping the trigger
+func (game *Game) updateTriggerOccupant(trigger *trigger, entity collidable, enemyIndex int) {
+	wasInside := trigger.occupants[entity]
+	isInside := checkForStaticOnStaticCollision(entity, trigger)
+
+	var eventType GameEventType
+	if isInside && !wasInside {
+		eventType = GameEventTriggerEnter
+		trigger.occupants[entity] = true
+	} else if isInside {
+		eventType = GameEventTriggerStay
+	} else if wasInside {
+		eventType = GameEventTriggerExit
+		delete(trigger.occupants, entity)
+	} else {
+		return
+	}
+
+	game.publish(GameEvent{Type: eventType, Trigger: trigger.index, Block: -1, Enemy: enemyIndex, trigger: trigger})
+}
+
+// shows the trigger's message when the player enters it
+func (game *Game) onTriggerEnterMessage(event GameEvent) {
+	if event.Enemy >= 0 || event.trigger.data.Message == "" {
+		return
+	}
+
+	game.publish(GameEvent{
+		Type:    GameEventMessage,
+		Trigger: event.Trigger,
+		Block:   -1,
+		Enemy:   event.Enemy,
+		Message: event.trigger.data.Message,
+		trigger: event.trigger,
+	})
+}
+
+func (trigger *trigger) render(game *Game, viewMatrix mgl32.Mat4) error {
+	return renderWireBox(game, viewMatrix, trigger.pos, trigger.scale, triggerColor)
+}
+
+// renders the outline of a box centered at pos
+func renderWireBox(game *Game, viewMatrix mgl32.Mat4, pos mgl32.Vec3, scale mgl32.Vec3, color mgl32.Vec4) error {
+	scaleMatrix := mgl32.Scale3D(scale.X(), scale.Y(), scale.Z())
+	translateMatrix := mgl32.Translate3D(pos.X(), pos.Y(), pos.Z())
+
+	modelMatrix := mgl32.Ident4().Mul4(translateMatrix).Mul4(scaleMatrix)
+
+	// not magic - shader is initialized with pointers to these values as uniforms
+	game.modelViewMatrix = viewMatrix.Mul4(modelMatrix)
+	game.normalMatrix = game.modelViewMatrix.Inv().Transpose()
+	game.color = color
+	game.material = mgl32.Vec4{1.0, 0.0, 0.0, 1.0} // flat (ambient only) so the outline reads the same from every angle
+
+	if err := game.gl.RenderLines(game.boxLineMesh, game.phongShader); err != nil {
+		return err
+	}
+
+	return nil
+}
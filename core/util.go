@@ -2,6 +2,8 @@ package core
 
 import (
 	"math"
+
+	"github.com/go-gl/mathgl/mgl32"
 )
 
 func f32Abs(num float32) float32 {
@@ -24,3 +26,31 @@ func f32Min(num1, num2 float32) float32 {
 func f32LimitBetween(num, min, max float32) float32 {
 	return f32Min(f32Max(num, min), max)
 }
+
+func lerpFloat32(from, to float32, t float32) float32 {
+	return from + (to-from)*t
+}
+
+func lerpVec3(from, to mgl32.Vec3, t float32) mgl32.Vec3 {
+	return from.Add(to.Sub(from).Mul(t))
+}
+
+func lerpVec4(from, to mgl32.Vec4, t float32) mgl32.Vec4 {
+	return from.Add(to.Sub(from).Mul(t))
+}
+
+func absInt32(num int32) int32 {
+	if num < 0 {
+		return -1 * num
+	}
+
+	return num
+}
+
+func maxInt32(num1, num2 int32) int32 {
+	if num1 > num2 {
+		return num1
+	}
+
+	return num2
+}
@@ -1,8 +1,6 @@
 package core
 
 import (
-	"fmt"
-
 	"github.com/go-gl/mathgl/mgl32"
 )
 
@@ -10,9 +8,81 @@ var worldBlockColorDefault = mgl32.Vec4{0.7, 0.7, 0.7, 1.0}
 var worldBlockColorHighlighted = mgl32.Vec4{.99, .84, .20, 1.0}
 
 type worldBlock struct {
-	pos   mgl32.Vec3
-	scale mgl32.Vec3
-	color mgl32.Vec4
+	pos       mgl32.Vec3
+	scale     mgl32.Vec3
+	color     mgl32.Vec4
+	baseColor mgl32.Vec4     // color when not highlighted
+	material  mgl32.Vec4     // vector of [Ka, Kd, Ks, shininess] (see Game.material)
+	data      worldBlockData // the block as authored in the level (exported as is so floats survive an export/import round trip)
+
+	kind            blockKind
+	surfaceVelocity mgl32.Vec3 // velocity anything standing on the block is carried along at
+
+	motion  blockMotion // how the block moves (nil for static blocks)
+	origin  mgl32.Vec3  // authored position of the block (moving blocks move relative to it)
+	prevPos mgl32.Vec3  // position at the start of the last simulation step
+	vel     mgl32.Vec3  // velocity of the block during the last simulation step
+
+	isPassable bool         // whether the block has crumbled away or been switched off by it's timer (see setWorldBlockPassable)
+	crumble    crumbleState // only used by crumbling blocks
+}
+
+// whether the block always stays where it is & solid (the only blocks enemies path find over)
+func (worldBlock *worldBlock) isStatic() bool {
+	return worldBlock.motion == nil && worldBlock.data.Crumble == nil && worldBlock.data.Timer == nil
+}
+
+// whether the block should be drawn (a crumbled block that vanished shouldn't be)
+func (worldBlock *worldBlock) isVisible() bool {
+	crumble := worldBlock.data.Crumble
+	return crumble == nil || worldBlock.crumble.phase != crumbleGone || crumbleMode(crumble.Mode) == crumbleModeFall
+}
+
+// name of the block's material - blocks that don't name one use their kind's material
+func (worldBlock *worldBlock) materialName() string {
+	if worldBlock.data.Material != "" {
+		return worldBlock.data.Material
+	}
+
+	return blockKinds[orSolidBlockKind(blockKind(worldBlock.data.Kind))].material
+}
+
+// sets the block's kind, material & color from it's data (an explicit color wins over the material's color)
+func (worldBlock *worldBlock) applyData(game *Game) {
+	worldBlock.kind = orSolidBlockKind(blockKind(worldBlock.data.Kind))
+	worldBlock.surfaceVelocity = blockKinds[worldBlock.kind].surfaceVelocity
+	if worldBlock.data.ConveyorVelocity != nil {
+		worldBlock.surfaceVelocity = mgl32.Vec3(*worldBlock.data.ConveyorVelocity)
+	}
+
+	worldBlock.motion = newBlockMotion(worldBlock.data.Motion)
+
+	material, isFound := findMaterial(game.materials, worldBlock.materialName())
+	if !isFound {
+		material = builtinMaterials[materialNameDefault]
+	}
+
+	worldBlock.material = material.vec4()
+	worldBlock.baseColor = worldBlockColorDefault
+	if worldBlock.data.Color != nil {
+		worldBlock.baseColor = mgl32.Vec4(*worldBlock.data.Color)
+	} else if material.Color != nil {
+		worldBlock.baseColor = mgl32.Vec4(*material.Color)
+	}
+	worldBlock.color = worldBlock.baseColor
+}
+
+// places the block at the given position (& holds it there - moving blocks move relative to it)
+func (worldBlock *worldBlock) place(pos mgl32.Vec3) {
+	worldBlock.origin = pos
+	worldBlock.pos = pos
+	worldBlock.prevPos = pos
+	worldBlock.vel = mgl32.Vec3{}
+}
+
+// position interpolated between the last two simulation steps
+func (worldBlock *worldBlock) interpolatedPos(t float32) mgl32.Vec3 {
+	return lerpVec3(worldBlock.prevPos, worldBlock.pos, t)
 }
 
 func (worldBlock *worldBlock) left() float32 {
@@ -39,18 +109,49 @@ func (worldBlock *worldBlock) back() float32 {
 	return worldBlock.pos.Z() - worldBlock.scale.Z()
 }
 
-func (worldBlock *worldBlock) update(game *Game, dt float32, inputs map[GameInput]bool) {
-	if game.IsEditModeEnabled && checkForStaticOnStaticCollision(game.player, worldBlock) {
-		worldBlock.color = worldBlockColorHighlighted
-		game.Log += fmt.Sprintf("<br/>World: (x: %.2f\ty: %.2f\tz: %.2f)", worldBlock.pos.X(), worldBlock.pos.Y(), worldBlock.pos.Z())
-	} else {
-		worldBlock.color = worldBlockColorDefault
+// adds a world block to the game & the broadphase index
+func (game *Game) addWorldBlock(worldBlock *worldBlock) {
+	game.insertWorldBlock(worldBlock, len(game.worldBlocks))
+}
+
+// adds a world block to the game (at the given index of game.worldBlocks) & the broadphase index
+func (game *Game) insertWorldBlock(worldBlock *worldBlock, index int) {
+	game.worldBlocks = append(game.worldBlocks, nil)
+	copy(game.worldBlocks[index+1:], game.worldBlocks[index:])
+	game.worldBlocks[index] = worldBlock
+
+	game.worldIndex.insert(worldBlock)
+	game.isNavGraphDirty = true
+}
+
+// removes a world block from the game & the broadphase index
+func (game *Game) removeWorldBlock(worldBlock *worldBlock) {
+	game.worldBlocks = withoutWorldBlock(game.worldBlocks, worldBlock)
+	game.worldIndex.remove(worldBlock)
+	game.isNavGraphDirty = true
+}
+
+// index of the world block in the level (-1 if it's not in the level)
+func (game *Game) worldBlockIndex(worldBlock *worldBlock) int {
+	for i, other := range game.worldBlocks {
+		if other == worldBlock {
+			return i
+		}
 	}
+
+	return -1
 }
 
 func (worldBlock *worldBlock) render(game *Game, viewMatrix mgl32.Mat4) error {
+	if !worldBlock.isVisible() {
+		return nil
+	}
+
+	pos := worldBlock.interpolatedPos(game.interpolation)
+	pos[1] -= lerpFloat32(worldBlock.crumble.prevFall, worldBlock.crumble.fallDistance, game.interpolation)
+
 	scaleMatrix := mgl32.Scale3D(worldBlock.scale.X(), worldBlock.scale.Y(), worldBlock.scale.Z())
-	translateMatrix := mgl32.Translate3D(worldBlock.pos.X(), worldBlock.pos.Y(), worldBlock.pos.Z())
+	translateMatrix := mgl32.Translate3D(pos.X(), pos.Y(), pos.Z())
 
 	modelMatrix := mgl32.Ident4().Mul4(translateMatrix).Mul4(scaleMatrix)
 
@@ -58,8 +159,8 @@ func (worldBlock *worldBlock) render(game *Game, viewMatrix mgl32.Mat4) error {
 	game.modelViewMatrix = viewMatrix.Mul4(modelMatrix)
 	game.normalMatrix = game.modelViewMatrix.Inv().Transpose()
 	game.color = worldBlock.color
-	game.material = mgl32.Vec4{0.1, 0.6, 0.0, 20.0}
-	game.lightPos = viewMatrix.Mul4x1(game.player.pos.Vec4(1.0)).Vec3()
+	game.material = worldBlock.material
+	game.lightPos = viewMatrix.Mul4x1(game.player.interpolatedPos(game.interpolation).Vec4(1.0)).Vec3()
 
 	// just always use phong...
 	shader := game.phongShader
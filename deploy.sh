@@ -14,7 +14,7 @@ rm -rf server &&
 rm -rf wasm &&
 rm -rf .vscode &&
 rm -f makefile go.mod go.sum &&
-cp static/* ./ &&
+cp -r static/* ./ &&
 rm -rf static &&
 
 echo "Committing and pushing to gh-pages" &&
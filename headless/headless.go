@@ -0,0 +1,546 @@
+package headless
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"os"
+
+	"github.com/cpoonolly/blockgame/core"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// nearPlaneEpsilon minimum clip space w a vertex can have before it's considered behind the camera
+const nearPlaneEpsilon float32 = 1e-5
+
+// Context a software rasterizer implementing core.GlContext that renders into an in-memory framebuffer
+type Context struct {
+	width          int
+	height         int
+	viewportWidth  int
+	viewportHeight int
+
+	frame   *image.RGBA
+	depth   []float32
+	enabled map[string]bool
+}
+
+// New initialize a new headless.Context with a framebuffer of the given size
+func New(width, height int) (*Context, error) {
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("invalid viewport size: %dx%d", width, height)
+	}
+
+	gl := new(Context)
+	gl.width = width
+	gl.height = height
+	gl.enabled = make(map[string]bool)
+
+	// calculate Viewport
+	gl.UpdateViewport()
+
+	return gl, nil
+}
+
+// SetViewportSize sets the size of the framebuffer (applied on the next call to UpdateViewport)
+func (gl *Context) SetViewportSize(width, height int) {
+	gl.width = width
+	gl.height = height
+}
+
+// UpdateViewport reallocates the framebuffer if the viewport size has changed
+func (gl *Context) UpdateViewport() {
+	if gl.frame != nil && gl.viewportWidth == gl.width && gl.viewportHeight == gl.height {
+		return
+	}
+
+	gl.viewportWidth = gl.width
+	gl.viewportHeight = gl.height
+	gl.frame = image.NewRGBA(image.Rect(0, 0, gl.width, gl.height))
+	gl.depth = make([]float32, gl.width*gl.height)
+
+	for i := range gl.depth {
+		gl.depth[i] = 1.0
+	}
+}
+
+// GetViewportWidth gets the viewport width
+func (gl *Context) GetViewportWidth() int {
+	return gl.viewportWidth
+}
+
+// GetViewportHeight gets the viewport height
+func (gl *Context) GetViewportHeight() int {
+	return gl.viewportHeight
+}
+
+// Enable enables a gl capability (only "CULL_FACE" & "DEPTH_TEST" affect rendering)
+func (gl *Context) Enable(constName string) {
+	gl.enabled[constName] = true
+}
+
+// Disable disables a gl capability
+func (gl *Context) Disable(constName string) {
+	gl.enabled[constName] = false
+}
+
+// ClearScreen clears the framebuffer to the given color & resets the depth buffer
+func (gl *Context) ClearScreen(colorR, colorG, colorB float32) error {
+	clearColor := toRGBA(mgl32.Vec3{colorR, colorG, colorB})
+
+	pix := gl.frame.Pix
+	for i := 0; i < len(pix); i += 4 {
+		pix[i+0] = clearColor.R
+		pix[i+1] = clearColor.G
+		pix[i+2] = clearColor.B
+		pix[i+3] = clearColor.A
+	}
+
+	for i := range gl.depth {
+		gl.depth[i] = 1.0
+	}
+
+	// depth testing is always on after a clear (same as the webgl context)
+	gl.enabled["DEPTH_TEST"] = true
+
+	return nil
+}
+
+// Image returns the framebuffer
+func (gl *Context) Image() *image.RGBA {
+	return gl.frame
+}
+
+// Depth returns the depth buffer value [0, 1] at the given pixel
+func (gl *Context) Depth(x, y int) float32 {
+	return gl.depth[y*gl.viewportWidth+x]
+}
+
+// WritePNG encodes the framebuffer as a png
+func (gl *Context) WritePNG(w io.Writer) error {
+	return png.Encode(w, gl.frame)
+}
+
+// SavePNG writes the framebuffer to a png file at the given path
+func (gl *Context) SavePNG(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := gl.WritePNG(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// RenderTriangles renders the triangles of the given mesh with the shader
+func (gl *Context) RenderTriangles(coreMesh core.Mesh, coreProgram core.ShaderProgram) error {
+	mesh, program, err := resolve(coreMesh, coreProgram)
+	if err != nil {
+		return err
+	}
+
+	verticies := program.transform(mesh)
+	for i := 0; i+2 < len(mesh.elements); i += 3 {
+		triangle := [3]vertex{
+			verticies[mesh.elements[i]],
+			verticies[mesh.elements[i+1]],
+			verticies[mesh.elements[i+2]],
+		}
+
+		clipped := clipPolygonToNearPlane(triangle[:])
+		for j := 1; j+1 < len(clipped); j++ {
+			gl.rasterizeTriangle(program, clipped[0], clipped[j], clipped[j+1])
+		}
+	}
+
+	return nil
+}
+
+// RenderLines renders the lines of the given mesh with the shader
+func (gl *Context) RenderLines(coreMesh core.Mesh, coreProgram core.ShaderProgram) error {
+	mesh, program, err := resolve(coreMesh, coreProgram)
+	if err != nil {
+		return err
+	}
+
+	verticies := program.transform(mesh)
+	for i := 0; i+1 < len(mesh.elements); i += 2 {
+		start, end, visible := clipLineToNearPlane(verticies[mesh.elements[i]], verticies[mesh.elements[i+1]])
+		if visible {
+			gl.rasterizeLine(program, start, end)
+		}
+	}
+
+	return nil
+}
+
+func resolve(coreMesh core.Mesh, coreProgram core.ShaderProgram) (*Mesh, *ShaderProgram, error) {
+	mesh, isHeadlessMesh := coreMesh.(*Mesh)
+	if !isHeadlessMesh {
+		return nil, nil, fmt.Errorf("mesh passed was not a headless mesh")
+	}
+
+	program, isHeadlessProgram := coreProgram.(*ShaderProgram)
+	if !isHeadlessProgram {
+		return nil, nil, fmt.Errorf("shader program passed was not a headless shader program")
+	}
+
+	return mesh, program, nil
+}
+
+// ShaderProgram a struct holding the uniforms of a shader program.
+// The glsl code itself isn't run - every program is shaded using the phong model from core's phong shader.
+type ShaderProgram struct {
+	vertCode string
+	fragCode string
+	uniforms map[string][]float32
+}
+
+// NewShaderProgram registers a shader program with the given uniforms
+func (gl *Context) NewShaderProgram(
+	vertCode string,
+	fragCode string,
+	uniforms map[string][]float32,
+) (core.ShaderProgram, error) {
+	program, err := newShaderProgram(vertCode, fragCode, uniforms)
+	if err != nil {
+		return nil, err
+	}
+
+	return program, nil
+}
+
+func newShaderProgram(vertCode string, fragCode string, uniforms map[string][]float32) (*ShaderProgram, error) {
+	program := new(ShaderProgram)
+	program.vertCode = vertCode
+	program.fragCode = fragCode
+	program.uniforms = make(map[string][]float32)
+
+	for uniformName, uniformVal := range uniforms {
+		if len(uniformVal) == 0 {
+			return nil, fmt.Errorf("invalid uniform '%s' passed to shader", uniformName)
+		}
+
+		// keep the slice itself (not a copy) so updates to the backing values are seen at draw time
+		program.uniforms[uniformName] = uniformVal
+	}
+
+	return program, nil
+}
+
+func (program *ShaderProgram) mat4(name string) mgl32.Mat4 {
+	var mat mgl32.Mat4
+	if val, ok := program.uniforms[name]; ok {
+		copy(mat[:], val)
+	} else {
+		mat = mgl32.Ident4()
+	}
+
+	return mat
+}
+
+func (program *ShaderProgram) vec4(name string) mgl32.Vec4 {
+	var vec mgl32.Vec4
+	copy(vec[:], program.uniforms[name])
+
+	return vec
+}
+
+func (program *ShaderProgram) vec3(name string) mgl32.Vec3 {
+	var vec mgl32.Vec3
+	copy(vec[:], program.uniforms[name])
+
+	return vec
+}
+
+// vertex a vertex after the vertex stage
+type vertex struct {
+	clip   mgl32.Vec4 // clip space position
+	pos    mgl32.Vec3 // view space position
+	normal mgl32.Vec3 // view space normal
+}
+
+func lerpVertex(v1, v2 vertex, t float32) vertex {
+	return vertex{
+		clip:   v1.clip.Add(v2.clip.Sub(v1.clip).Mul(t)),
+		pos:    v1.pos.Add(v2.pos.Sub(v1.pos).Mul(t)),
+		normal: v1.normal.Add(v2.normal.Sub(v1.normal).Mul(t)),
+	}
+}
+
+func (program *ShaderProgram) transform(mesh *Mesh) []vertex {
+	matP := program.mat4("uMatP")
+	matMV := program.mat4("uMatMV")
+	matNorm := program.mat4("uMatNorm")
+
+	verticies := make([]vertex, len(mesh.verticies)/3)
+	for i := range verticies {
+		position := mgl32.Vec3{mesh.verticies[3*i], mesh.verticies[3*i+1], mesh.verticies[3*i+2]}
+		normal := mgl32.Vec3{mesh.normals[3*i], mesh.normals[3*i+1], mesh.normals[3*i+2]}
+
+		pos := matMV.Mul4x1(position.Vec4(1.0))
+		verticies[i].pos = pos.Vec3()
+		verticies[i].clip = matP.Mul4x1(pos)
+		verticies[i].normal = matNorm.Mul4x1(normal.Vec4(0.0)).Vec3()
+	}
+
+	return verticies
+}
+
+// shade computes the color of a fragment the same way core's phong fragment shader does
+func (program *ShaderProgram) shade(pos, normal mgl32.Vec3) mgl32.Vec3 {
+	color := program.vec4("uColor").Vec3()
+	material := program.vec4("uMaterial")
+	eyePos := program.vec3("uEyePos")
+	lightPos := program.vec3("uLightPos")
+
+	ka, kd, ks, shininess := material[0], material[1], material[2], material[3]
+
+	ambient := color.Mul(ka)
+
+	lightDist := lightPos.Sub(pos).Len() * 0.8
+	L := normalize(lightPos.Sub(pos))
+	N := normalize(normal)
+	lambert := float32(math.Max(float64(N.Dot(L)), 0.0))
+
+	var diffuse mgl32.Vec3
+	if lightDist > 0 {
+		diffuse = color.Mul(lambert * kd / lightDist)
+	}
+
+	var specular mgl32.Vec3
+	if lambert > 0.0 {
+		R := L.Mul(-1.0).Sub(N.Mul(2.0 * N.Dot(L.Mul(-1.0))))
+		V := normalize(eyePos)
+		specular = color.Mul(float32(math.Pow(math.Max(float64(R.Dot(V)), 0.0), float64(shininess))) * ks)
+	}
+
+	return ambient.Add(diffuse).Add(specular)
+}
+
+// Mesh a struct for managing a mesh of verticies, normals & elements
+type Mesh struct {
+	verticies []float32
+	normals   []float32
+	elements  []uint16
+}
+
+// NewMesh creates a new mesh (meshes are simply combinations of verticies & elments)
+func (gl *Context) NewMesh(verticies []float32, normals []float32, elements []uint16) (core.Mesh, error) {
+	mesh, err := newMesh(verticies, normals, elements)
+	if err != nil {
+		return nil, err
+	}
+
+	return mesh, nil
+}
+
+func newMesh(verticies []float32, normals []float32, elements []uint16) (*Mesh, error) {
+	if len(verticies)%3 != 0 || len(normals) != len(verticies) {
+		return nil, fmt.Errorf("mesh must have 3 components per vertex & one normal per vertex")
+	}
+
+	for _, element := range elements {
+		if int(element) >= len(verticies)/3 {
+			return nil, fmt.Errorf("mesh element %d out of range", element)
+		}
+	}
+
+	mesh := new(Mesh)
+	mesh.verticies = append([]float32(nil), verticies...)
+	mesh.normals = append([]float32(nil), normals...)
+	mesh.elements = append([]uint16(nil), elements...)
+
+	return mesh, nil
+}
+
+// clips a polygon against the near plane (z >= -w) in clip space (Sutherland–Hodgman)
+func clipPolygonToNearPlane(polygon []vertex) []vertex {
+	clipped := make([]vertex, 0, len(polygon)+1)
+
+	for i := range polygon {
+		current := polygon[i]
+		next := polygon[(i+1)%len(polygon)]
+
+		currentDist := current.clip.Z() + current.clip.W()
+		nextDist := next.clip.Z() + next.clip.W()
+
+		if currentDist >= 0 {
+			clipped = append(clipped, current)
+		}
+
+		if (currentDist >= 0) != (nextDist >= 0) {
+			clipped = append(clipped, lerpVertex(current, next, currentDist/(currentDist-nextDist)))
+		}
+	}
+
+	return clipped
+}
+
+// clips a line against the near plane (z >= -w) in clip space
+func clipLineToNearPlane(start, end vertex) (vertex, vertex, bool) {
+	startDist := start.clip.Z() + start.clip.W()
+	endDist := end.clip.Z() + end.clip.W()
+
+	if startDist < 0 && endDist < 0 {
+		return start, end, false
+	}
+
+	if startDist < 0 {
+		start = lerpVertex(start, end, startDist/(startDist-endDist))
+	} else if endDist < 0 {
+		end = lerpVertex(end, start, endDist/(endDist-startDist))
+	}
+
+	return start, end, true
+}
+
+// screen space position of a vertex (x/y in pixels, z in [0, 1] depth range) plus 1/w for perspective correction
+func (gl *Context) toScreen(v vertex) (mgl32.Vec3, float32) {
+	w := v.clip.W()
+	if w < nearPlaneEpsilon {
+		w = nearPlaneEpsilon
+	}
+
+	ndc := v.clip.Vec3().Mul(1.0 / w)
+
+	return mgl32.Vec3{
+		(ndc.X() + 1.0) * 0.5 * float32(gl.viewportWidth),
+		(1.0 - ndc.Y()) * 0.5 * float32(gl.viewportHeight),
+		ndc.Z()*0.5 + 0.5,
+	}, 1.0 / w
+}
+
+func (gl *Context) rasterizeTriangle(program *ShaderProgram, v0, v1, v2 vertex) {
+	s0, invW0 := gl.toScreen(v0)
+	s1, invW1 := gl.toScreen(v1)
+	s2, invW2 := gl.toScreen(v2)
+
+	area := edge(s0, s1, s2)
+	if area == 0 {
+		return
+	}
+
+	// screen space is y-down which flips the winding - front facing (counter clockwise) triangles have a positive area
+	if gl.enabled["CULL_FACE"] && area < 0 {
+		return
+	}
+
+	minX := clampInt(int(math.Floor(float64(min3(s0.X(), s1.X(), s2.X())))), 0, gl.viewportWidth-1)
+	maxX := clampInt(int(math.Ceil(float64(max3(s0.X(), s1.X(), s2.X())))), 0, gl.viewportWidth-1)
+	minY := clampInt(int(math.Floor(float64(min3(s0.Y(), s1.Y(), s2.Y())))), 0, gl.viewportHeight-1)
+	maxY := clampInt(int(math.Ceil(float64(max3(s0.Y(), s1.Y(), s2.Y())))), 0, gl.viewportHeight-1)
+
+	for y := minY; y <= maxY; y++ {
+		for x := minX; x <= maxX; x++ {
+			p := mgl32.Vec3{float32(x) + 0.5, float32(y) + 0.5, 0}
+
+			b0 := edge(s1, s2, p) / area
+			b1 := edge(s2, s0, p) / area
+			b2 := edge(s0, s1, p) / area
+			if b0 < 0 || b1 < 0 || b2 < 0 {
+				continue
+			}
+
+			depth := b0*s0.Z() + b1*s1.Z() + b2*s2.Z()
+
+			// perspective correct interpolation of the view space attributes
+			w0, w1, w2 := b0*invW0, b1*invW1, b2*invW2
+			wSum := w0 + w1 + w2
+			pos := v0.pos.Mul(w0).Add(v1.pos.Mul(w1)).Add(v2.pos.Mul(w2)).Mul(1.0 / wSum)
+			normal := v0.normal.Mul(w0).Add(v1.normal.Mul(w1)).Add(v2.normal.Mul(w2)).Mul(1.0 / wSum)
+
+			gl.writeFragment(program, x, y, depth, pos, normal)
+		}
+	}
+}
+
+func (gl *Context) rasterizeLine(program *ShaderProgram, v0, v1 vertex) {
+	s0, invW0 := gl.toScreen(v0)
+	s1, invW1 := gl.toScreen(v1)
+
+	steps := int(math.Ceil(math.Max(math.Abs(float64(s1.X()-s0.X())), math.Abs(float64(s1.Y()-s0.Y())))))
+	if steps == 0 {
+		steps = 1
+	}
+
+	for i := 0; i <= steps; i++ {
+		t := float32(i) / float32(steps)
+		p := s0.Add(s1.Sub(s0).Mul(t))
+
+		x, y := int(math.Floor(float64(p.X()))), int(math.Floor(float64(p.Y())))
+		if x < 0 || x >= gl.viewportWidth || y < 0 || y >= gl.viewportHeight {
+			continue
+		}
+
+		w0, w1 := (1-t)*invW0, t*invW1
+		pos := v0.pos.Mul(w0).Add(v1.pos.Mul(w1)).Mul(1.0 / (w0 + w1))
+		normal := v0.normal.Mul(w0).Add(v1.normal.Mul(w1)).Mul(1.0 / (w0 + w1))
+
+		gl.writeFragment(program, x, y, p.Z(), pos, normal)
+	}
+}
+
+func (gl *Context) writeFragment(program *ShaderProgram, x, y int, depth float32, pos, normal mgl32.Vec3) {
+	if depth < 0 || depth > 1 {
+		return
+	}
+
+	i := y*gl.viewportWidth + x
+	if gl.enabled["DEPTH_TEST"] {
+		if depth > gl.depth[i] {
+			return
+		}
+		gl.depth[i] = depth
+	}
+
+	gl.frame.SetRGBA(x, y, toRGBA(program.shade(pos, normal)))
+}
+
+// edge function - twice the signed area of the triangle (a, b, c) in screen space
+func edge(a, b, c mgl32.Vec3) float32 {
+	return (c.X()-a.X())*(b.Y()-a.Y()) - (c.Y()-a.Y())*(b.X()-a.X())
+}
+
+func normalize(vec mgl32.Vec3) mgl32.Vec3 {
+	if vec.Len() == 0 {
+		return vec
+	}
+
+	return vec.Normalize()
+}
+
+func toRGBA(rgb mgl32.Vec3) color.RGBA {
+	return color.RGBA{toColorChannel(rgb.X()), toColorChannel(rgb.Y()), toColorChannel(rgb.Z()), 255}
+}
+
+func toColorChannel(val float32) uint8 {
+	return uint8(math.Round(math.Min(math.Max(float64(val), 0.0), 1.0) * 255.0))
+}
+
+func min3(a, b, c float32) float32 {
+	return float32(math.Min(float64(a), math.Min(float64(b), float64(c))))
+}
+
+func max3(a, b, c float32) float32 {
+	return float32(math.Max(float64(a), math.Max(float64(b), float64(c))))
+}
+
+func clampInt(num, min, max int) int {
+	if num < min {
+		return min
+	}
+
+	if num > max {
+		return max
+	}
+
+	return num
+}
@@ -0,0 +1,127 @@
+package headless
+
+import (
+	"image/color"
+	"testing"
+
+	"github.com/cpoonolly/blockgame/core"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// a player standing on a single glowing block
+const staticLevel = `{
+	"version": 2,
+	"player": {"position": [-0.5, 1, -0.5], "dimensions": [1, 1, 1]},
+	"world": [
+		{"position": [-2, 0, -3], "dimensions": [4, 1, 6], "material": "glow", "color": [0.8, 0.2, 0.1, 1]}
+	],
+	"enemies": []
+}`
+
+var staticLevelBlockColor = mgl32.Vec4{0.8, 0.2, 0.1, 1}
+
+// the glow material (ambient, diffuse, specular, shininess)
+var staticLevelBlockMaterial = mgl32.Vec4{1, 0, 0, 1}
+
+var playerColor = mgl32.Vec4{0.3, 0.5, 1.0, 1.0}
+
+// renders the static level after a single simulation step (so the camera is following the player)
+func renderStaticLevel(t *testing.T, gl core.GlContext) *core.Game {
+	game, err := core.NewGame(gl)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := game.ImportFromJSON(staticLevel); err != nil {
+		t.Fatal(err)
+	}
+
+	game.Update(1000.0/60.0, nil)
+	game.Render()
+
+	return game
+}
+
+// length of each of the model view matrix's axes - the scale of the model drawn (the view matrix doesn't scale)
+func modelViewScale(call DrawCall) mgl32.Vec3 {
+	return mgl32.Vec3{call.ModelView.Col(0).Vec3().Len(), call.ModelView.Col(1).Vec3().Len(), call.ModelView.Col(2).Vec3().Len()}
+}
+
+func TestRecorderRendersStaticLevel(t *testing.T) {
+	recorder := NewRecorder(320, 240)
+	renderStaticLevel(t, recorder)
+
+	if len(recorder.Calls) == 0 || recorder.Calls[0].Type != DrawCallClearScreen {
+		t.Fatalf("expected the frame to start with a clear screen, got %v", recorder.Calls)
+	}
+	if clearColor := recorder.Calls[0].ClearColor; clearColor != (mgl32.Vec3{}) {
+		t.Errorf("expected the screen to be cleared to black outside edit mode, got %v", clearColor)
+	}
+
+	playerCalls := recorder.CallsWithColor(playerColor)
+	if len(playerCalls) != 1 || playerCalls[0].Type != DrawCallTriangles {
+		t.Fatalf("expected the player to be drawn once, got %v", playerCalls)
+	}
+	blockCalls := recorder.CallsWithColor(staticLevelBlockColor)
+	if len(blockCalls) != 1 || blockCalls[0].Type != DrawCallTriangles {
+		t.Fatalf("expected the world block to be drawn once, got %v", blockCalls)
+	}
+	player, block := playerCalls[0], blockCalls[0]
+
+	if !block.Material.ApproxEqual(staticLevelBlockMaterial) {
+		t.Errorf("expected the world block to be drawn with the glow material %v, got %v", staticLevelBlockMaterial, block.Material)
+	}
+
+	if scale := modelViewScale(player); !scale.ApproxEqualThreshold(mgl32.Vec3{0.5, 0.5, 0.5}, 1e-4) {
+		t.Errorf("expected the player to be drawn at half it's size (the block mesh is 2 units across), got %v", scale)
+	}
+	if scale := modelViewScale(block); !scale.ApproxEqualThreshold(mgl32.Vec3{2, 0.5, 3}, 1e-4) {
+		t.Errorf("expected the world block to be drawn at half it's size, got %v", scale)
+	}
+
+	// the player's center stands 1 above the block's (the view matrix doesn't change distances)
+	if distance := player.Position().Sub(block.Position()).Len(); mgl32.Abs(distance-1) > 1e-3 {
+		t.Errorf("expected the player to be drawn 1 from the world block, got %v", distance)
+	}
+
+	// the camera looks at the player
+	if position := player.Position(); mgl32.Abs(position.X()) > 1e-3 || mgl32.Abs(position.Y()) > 1e-3 || position.Z() >= 0 {
+		t.Errorf("expected the player to be drawn straight in front of the camera, got %v", position)
+	}
+}
+
+func TestContextRastersStaticLevel(t *testing.T) {
+	gl, err := New(64, 48)
+	if err != nil {
+		t.Fatal(err)
+	}
+	renderStaticLevel(t, gl)
+
+	frame := gl.Image()
+
+	// the camera looks at the player so the player's blue is in the middle of the frame
+	center := frame.RGBAAt(32, 24)
+	if center.B <= center.R || center.B <= center.G {
+		t.Errorf("expected the player's blue in the middle of the frame, got %v", center)
+	}
+
+	// the corners are empty
+	black := color.RGBA{0, 0, 0, 255}
+	for _, corner := range [][2]int{{0, 0}, {63, 0}} {
+		if pixel := frame.RGBAAt(corner[0], corner[1]); pixel != black {
+			t.Errorf("expected the clear color at %v, got %v", corner, pixel)
+		}
+	}
+
+	// the glowing block is drawn somewhere below the player
+	hasBlock := false
+	for y := 24; y < 48; y++ {
+		for x := 0; x < 64; x++ {
+			pixel := frame.RGBAAt(x, y)
+			hasBlock = hasBlock || (pixel.R > pixel.G && pixel.R > pixel.B)
+		}
+	}
+	if !hasBlock {
+		t.Errorf("expected the world block's red below the player")
+	}
+}
@@ -0,0 +1,183 @@
+package headless
+
+import (
+	"github.com/cpoonolly/blockgame/core"
+	"github.com/go-gl/mathgl/mgl32"
+)
+
+// DrawCallType the type of a recorded draw call
+type DrawCallType int
+
+const (
+	// DrawCallClearScreen a call to ClearScreen
+	DrawCallClearScreen DrawCallType = iota + 1
+	// DrawCallTriangles a call to RenderTriangles
+	DrawCallTriangles
+	// DrawCallLines a call to RenderLines
+	DrawCallLines
+)
+
+// DrawCall a recorded call along with a snapshot of the uniforms bound at the time of the call
+type DrawCall struct {
+	Type       DrawCallType
+	ClearColor mgl32.Vec3 // only set for DrawCallClearScreen
+
+	Mesh      core.Mesh
+	Shader    core.ShaderProgram
+	ModelView mgl32.Mat4
+	Color     mgl32.Vec4
+	Material  mgl32.Vec4
+	LightPos  mgl32.Vec3
+}
+
+// Position the view space position of the model drawn (the translation of the model view matrix)
+func (call DrawCall) Position() mgl32.Vec3 {
+	return call.ModelView.Col(3).Vec3()
+}
+
+// Recorder a core.GlContext that doesn't draw anything - it records every draw call made instead
+type Recorder struct {
+	width   int
+	height  int
+	enabled map[string]bool
+
+	Calls []DrawCall
+}
+
+// NewRecorder initialize a new Recorder with a viewport of the given size
+func NewRecorder(width, height int) *Recorder {
+	recorder := new(Recorder)
+	recorder.width = width
+	recorder.height = height
+	recorder.enabled = make(map[string]bool)
+
+	return recorder
+}
+
+// Reset clears all recorded calls
+func (recorder *Recorder) Reset() {
+	recorder.Calls = nil
+}
+
+// Filter returns all recorded calls matching the given predicate
+func (recorder *Recorder) Filter(predicate func(DrawCall) bool) []DrawCall {
+	calls := make([]DrawCall, 0)
+	for _, call := range recorder.Calls {
+		if predicate(call) {
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// CallsOfType returns all recorded calls of the given type
+func (recorder *Recorder) CallsOfType(callType DrawCallType) []DrawCall {
+	return recorder.Filter(func(call DrawCall) bool {
+		return call.Type == callType
+	})
+}
+
+// CallsWithColor returns all recorded triangle & line calls drawn with the given color
+func (recorder *Recorder) CallsWithColor(color mgl32.Vec4) []DrawCall {
+	return recorder.Filter(func(call DrawCall) bool {
+		return call.Type != DrawCallClearScreen && call.Color.ApproxEqual(color)
+	})
+}
+
+// SetViewportSize sets the viewport size reported to the game
+func (recorder *Recorder) SetViewportSize(width, height int) {
+	recorder.width = width
+	recorder.height = height
+}
+
+// UpdateViewport no-op - the viewport size is set via SetViewportSize
+func (recorder *Recorder) UpdateViewport() {}
+
+// GetViewportWidth gets the viewport width
+func (recorder *Recorder) GetViewportWidth() int {
+	return recorder.width
+}
+
+// GetViewportHeight gets the viewport height
+func (recorder *Recorder) GetViewportHeight() int {
+	return recorder.height
+}
+
+// Enable enables a gl capability
+func (recorder *Recorder) Enable(constName string) {
+	recorder.enabled[constName] = true
+}
+
+// Disable disables a gl capability
+func (recorder *Recorder) Disable(constName string) {
+	recorder.enabled[constName] = false
+}
+
+// IsEnabled whether the given gl capability is currently enabled
+func (recorder *Recorder) IsEnabled(constName string) bool {
+	return recorder.enabled[constName]
+}
+
+// ClearScreen records a clear screen
+func (recorder *Recorder) ClearScreen(colorR, colorG, colorB float32) error {
+	recorder.Calls = append(recorder.Calls, DrawCall{
+		Type:       DrawCallClearScreen,
+		ClearColor: mgl32.Vec3{colorR, colorG, colorB},
+	})
+
+	return nil
+}
+
+// RenderTriangles records a render of the triangles of the given mesh
+func (recorder *Recorder) RenderTriangles(coreMesh core.Mesh, coreProgram core.ShaderProgram) error {
+	return recorder.record(DrawCallTriangles, coreMesh, coreProgram)
+}
+
+// RenderLines records a render of the lines of the given mesh
+func (recorder *Recorder) RenderLines(coreMesh core.Mesh, coreProgram core.ShaderProgram) error {
+	return recorder.record(DrawCallLines, coreMesh, coreProgram)
+}
+
+func (recorder *Recorder) record(callType DrawCallType, coreMesh core.Mesh, coreProgram core.ShaderProgram) error {
+	_, program, err := resolve(coreMesh, coreProgram)
+	if err != nil {
+		return err
+	}
+
+	recorder.Calls = append(recorder.Calls, DrawCall{
+		Type:      callType,
+		Mesh:      coreMesh,
+		Shader:    coreProgram,
+		ModelView: program.mat4("uMatMV"),
+		Color:     program.vec4("uColor"),
+		Material:  program.vec4("uMaterial"),
+		LightPos:  program.vec3("uLightPos"),
+	})
+
+	return nil
+}
+
+// NewShaderProgram registers a shader program with the given uniforms
+func (recorder *Recorder) NewShaderProgram(
+	vertCode string,
+	fragCode string,
+	uniforms map[string][]float32,
+) (core.ShaderProgram, error) {
+	program, err := newShaderProgram(vertCode, fragCode, uniforms)
+	if err != nil {
+		return nil, err
+	}
+
+	return program, nil
+}
+
+// NewMesh creates a new mesh
+func (recorder *Recorder) NewMesh(verticies []float32, normals []float32, elements []uint16) (core.Mesh, error) {
+	mesh, err := newMesh(verticies, normals, elements)
+	if err != nil {
+		return nil, err
+	}
+
+	return mesh, nil
+}
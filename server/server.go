@@ -6,6 +6,7 @@ import (
 	"flag"
 	"log"
 	"net/http"
+	"strings"
 )
 
 var (
@@ -16,6 +17,17 @@ var (
 func main() {
 	flag.Parse()
 	log.Printf("listening on %q...", *listen)
-	err := http.ListenAndServe(*listen, http.FileServer(http.Dir(*dir)))
+	err := http.ListenAndServe(*listen, noCacheJSON(http.FileServer(http.Dir(*dir))))
 	log.Fatalln(err)
 }
+
+// levels (& level packs) are json - they're edited often so make sure browsers always fetch the latest
+func noCacheJSON(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, ".json") {
+			w.Header().Set("Cache-Control", "no-cache")
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
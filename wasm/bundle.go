@@ -2,6 +2,9 @@ package main
 
 import (
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
 	"strconv"
 	"syscall/js"
 
@@ -21,6 +24,57 @@ func clearMap(m map[string]bool) {
 	}
 }
 
+// level pack loaded when the page doesn't name one (with ?pack=<url>)
+const defaultLevelPackURL = "levels.json"
+
+// resolves a url relative to another (absolute) url
+func resolveURL(base, ref string) (string, error) {
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+
+	refURL, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+
+	return baseURL.ResolveReference(refURL).String(), nil
+}
+
+// fetches the document at the given (absolute) url - blocks so mustn't be called directly from a js callback
+func fetch(url string) (string, error) {
+	resp, err := http.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("fetching %s: %s", url, resp.Status)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return string(body), nil
+}
+
+// how long (ms) a message stays in the HUD
+const hudMessageDuration float32 = 2000
+
+// stats of a run as lines of html
+func formatStats(stats core.GameStats) string {
+	seconds := int(stats.ElapsedTime / 1000)
+
+	return fmt.Sprintf(
+		"Max Height: %.1f<br/>Time: %d:%02d<br/>Jumps: %d<br/>Deaths: %d<br/>Distance: %.1f",
+		stats.MaxHeight, seconds/60, seconds%60, stats.Jumps, stats.Deaths, stats.Distance,
+	)
+}
+
 func main() {
 	gl, err = webgl.New("canvas_main")
 	if err != nil {
@@ -63,14 +117,152 @@ func main() {
 		return nil
 	})
 
-	/* Main Game Loop */
+	/* Mouse Picking */
+
+	// in edit mode clicking a block or enemy selects it (ctrl clicking adds it to the selection) - shift clicking places a new
+	// block against the face clicked
+	onCanvasMouseDown := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		event := args[0]
+		if !game.IsEditModeEnabled || event.Get("button").Int() != 0 {
+			return nil
+		}
+
+		x := float32(event.Get("offsetX").Float())
+		y := float32(event.Get("offsetY").Float())
+		if event.Get("shiftKey").Bool() {
+			game.PlaceBlockAt(x, y)
+		} else if event.Get("ctrlKey").Bool() || event.Get("metaKey").Bool() {
+			game.ToggleSelectionAt(x, y)
+		} else {
+			game.SelectAt(x, y)
+		}
+
+		return nil
+	})
+
+	/* Levels */
+
+	var levelPack *core.LevelPack
+	var levelPackURL string
+	var levelIndex int
+
+	// loads & starts the level at the given index of the level pack (blocks while the level is fetched)
+	loadLevel := func(index int) error {
+		levelURL, err := resolveURL(levelPackURL, levelPack.Levels[index].URL)
+		if err != nil {
+			return err
+		}
+
+		levelJSON, err := fetch(levelURL)
+		if err != nil {
+			return err
+		}
+
+		if err := game.ImportFromJSON(levelJSON); err != nil {
+			return fmt.Errorf("%s: %v", levelURL, err)
+		}
+		levelIndex = index
+
+		// always record so a replay of the current run can be exported (ex. to attach to a bug report)
+		return game.StartRecording()
+	}
+
+	/* Editor History */
+
+	// undo/redo (& snap grid) clicked in the editor panel (applied on the next frame like a key press)
+	var isUndoClicked, isRedoClicked, isGridClicked bool
+	var editorHistoryHTML string
+
+	// lists the changes that can be undone (& the undone ones that can be redone) in the editor panel
+	updateEditorHistory := func() {
+		history := game.EditorHistory()
+
+		html := ""
+		for _, description := range history.Undo {
+			html += "<li>" + description + "</li>"
+		}
+		for _, description := range history.Redo {
+			html += "<li class='undone'>" + description + "</li>"
+		}
+
+		if html != editorHistoryHTML {
+			editorHistoryHTML = html
+			gl.DocumentEl.Call("getElementById", "editor-history").Set("innerHTML", html)
+		}
+	}
+
+	/* Prefabs */
+
+	var prefabListHTML string
+
+	// lists the level's prefabs in the editor panel (keeping whichever is picked)
+	updatePrefabList := func() {
+		html := ""
+		for _, name := range game.PrefabNames() {
+			html += "<option>" + name + "</option>"
+		}
+
+		if html != prefabListHTML {
+			prefabListHTML = html
+			prefabList := gl.DocumentEl.Call("getElementById", "prefab-list")
+			picked := prefabList.Get("value").String()
+			prefabList.Set("innerHTML", html)
+			prefabList.Set("value", picked)
+		}
+	}
+
+	/* HUD */
+
+	var hudMessage string
+	var hudMessageTime float32
+
+	// now is the current time (ms) - messages are only shown for hudMessageDuration
+	updateHud := func(now float32) {
+		hud := fmt.Sprintf("%s<br/>Lives: %d<br/>%s", levelPack.Levels[levelIndex].Name, game.Lives(), formatStats(game.Stats()))
+		if hudMessage != "" && now-hudMessageTime < hudMessageDuration {
+			hud += "<br/><br/>" + hudMessage
+		}
+
+		gl.DocumentEl.Call("getElementById", "game_hud").Set("innerHTML", hud)
+	}
+
+	/* Game Events */
 
 	var lastRenderTime float32
+
+	// events are published during game.Update (so lastRenderTime is the time of the frame)
+	showHudMessage := func(message string) {
+		hudMessage, hudMessageTime = message, lastRenderTime
+	}
+
+	game.Subscribe(core.GameEventCheckpoint, func(event core.GameEvent) {
+		showHudMessage("Checkpoint reached!")
+	})
+	game.Subscribe(core.GameEventRespawn, func(event core.GameEvent) {
+		showHudMessage("Ouch! Back to the last checkpoint")
+	})
+	game.Subscribe(core.GameEventMessage, func(event core.GameEvent) {
+		showHudMessage(event.Message)
+	})
+
+	/* Main Game Loop */
+
+	var isRenderLoopRunning bool
+	var isEditModeShown bool // whether the page is laid out for edit mode (restarts & replays can change edit mode too)
 	var renderFrame js.Func
 	renderFrame = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		// if game is over invoke call back and don't request another animation frame
 		if game.IsGameOver {
-			js.Global().Call("onGameOver")
+			isRenderLoopRunning = false
+			js.Global().Call("onGameOver", formatStats(game.Stats()))
+			return nil
+		}
+
+		// same for completing the level (the page moves on to the next level with nextLevel)
+		if game.IsLevelComplete {
+			isRenderLoopRunning = false
+			hasNextLevel := levelIndex+1 < len(levelPack.Levels)
+			js.Global().Call("onLevelComplete", levelPack.Levels[levelIndex].Name, formatStats(game.Stats()), hasNextLevel)
 			return nil
 		}
 
@@ -141,26 +333,159 @@ func main() {
 		if isKeyDownMap["KeyD"] {
 			inputMap[core.GameInputEditModeDelete] = true
 		}
+		if isKeyDownMap["KeyP"] {
+			inputMap[core.GameInputEditModeAddWaypoint] = true
+		}
+		if isKeyDownMap["KeyO"] {
+			inputMap[core.GameInputEditModeEndPath] = true
+		}
+		if isKeyDownMap["KeyL"] {
+			inputMap[core.GameInputEditModeTogglePathMode] = true
+		}
+		if isKeyDownMap["KeyM"] {
+			inputMap[core.GameInputEditModeCycleMaterial] = true
+		}
+		if isKeyDownMap["KeyK"] {
+			inputMap[core.GameInputEditModeCycleBlockKind] = true
+		}
+		if isKeyDownMap["KeyN"] {
+			inputMap[core.GameInputEditModeCycleMotion] = true
+		}
+		if wasKeyPressedMap["KeyZ"] || isUndoClicked {
+			inputMap[core.GameInputEditModeUndo] = true
+		}
+		if wasKeyPressedMap["KeyY"] || isRedoClicked {
+			inputMap[core.GameInputEditModeRedo] = true
+		}
+		if wasKeyPressedMap["KeyC"] {
+			inputMap[core.GameInputEditModeSelect] = true
+		}
+		if wasKeyPressedMap["KeyR"] {
+			inputMap[core.GameInputEditModeCycleFace] = true
+		}
+		if wasKeyPressedMap["KeyG"] || isGridClicked {
+			inputMap[core.GameInputEditModeCycleGridSize] = true
+		}
+		if wasKeyPressedMap["KeyX"] {
+			inputMap[core.GameInputEditModeSelectMore] = true
+		}
+		if wasKeyPressedMap["KeyB"] {
+			inputMap[core.GameInputEditModeCopy] = true
+		}
+		if wasKeyPressedMap["KeyV"] {
+			inputMap[core.GameInputEditModePaste] = true
+		}
+		if wasKeyPressedMap["KeyJ"] {
+			inputMap[core.GameInputEditModeDuplicate] = true
+		}
+		isUndoClicked, isRedoClicked, isGridClicked = false, false, false
 
 		game.Update(dt, inputMap)
 		game.Render()
 
+		updateHud(now)
+		if game.IsEditModeEnabled {
+			updateEditorHistory()
+			updatePrefabList()
+		}
+
 		js.Global().Call("requestAnimationFrame", renderFrame)
 		clearMap(wasKeyPressedMap)
 
-		if inputMap[core.GameInputEditModeToggle] {
-			gl.DocumentEl.Call("getElementById", "container_main").Get("classList").Call("toggle", "edit-mode-enabled")
+		isEditModeChanged := game.IsEditModeEnabled != isEditModeShown
+		if isEditModeChanged {
+			isEditModeShown = game.IsEditModeEnabled
+			gl.DocumentEl.Call("getElementById", "container_main").Get("classList").Call("toggle", "edit-mode-enabled", isEditModeShown)
 			game.OnViewPortChange()
 		}
 
-		if len(game.Log) > 0 || inputMap[core.GameInputEditModeToggle] {
+		if len(game.Log) > 0 || isEditModeChanged {
 			gl.DocumentEl.Call("getElementById", "game_log").Set("innerHTML", game.Log)
 		}
 		return nil
 	})
 
+	// starts the render loop if the game ending stopped it (& hides the game over/level complete screens)
+	startRenderLoop := func() {
+		gl.DocumentEl.Call("getElementById", "game_over").Get("style").Set("display", "none")
+		gl.DocumentEl.Call("getElementById", "level_complete").Get("style").Set("display", "none")
+
+		if !isRenderLoopRunning {
+			isRenderLoopRunning = true
+			js.Global().Call("requestAnimationFrame", renderFrame)
+		}
+	}
+
+	// moves on to the next level of the level pack (back to the first after the last)
+	nextLevel := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		// fetching blocks so can't be done on the js callback
+		go func() {
+			if err := loadLevel((levelIndex + 1) % len(levelPack.Levels)); err != nil {
+				js.Global().Call("alert", err.Error())
+				return
+			}
+
+			startRenderLoop()
+		}()
+
+		return nil
+	})
+
 	/* Editor Actions */
 
+	undoEdit := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		isUndoClicked = true
+
+		return nil
+	})
+
+	redoEdit := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		isRedoClicked = true
+
+		return nil
+	})
+
+	cycleGridSize := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		isGridClicked = true
+
+		return nil
+	})
+
+	// snaps the whole level to the editor's grid (can be undone like any other edit)
+	quantizeLevel := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		gridSize := game.EditorGridSize()
+		if gridSize <= 0 {
+			js.Global().Call("alert", "turn on the snap grid (G) to quantize the level")
+			return nil
+		}
+
+		if err := game.QuantizeLevel(gridSize); err != nil {
+			js.Global().Call("alert", err.Error())
+		}
+
+		return nil
+	})
+
+	// saves the selection as a prefab with the name in the editor panel
+	savePrefab := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		name := gl.DocumentEl.Call("getElementById", "prefab-name").Get("value").String()
+		if err := game.SavePrefab(name); err != nil {
+			js.Global().Call("alert", err.Error())
+		}
+
+		return nil
+	})
+
+	// places the prefab picked in the editor panel where the player is
+	placePrefab := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		name := gl.DocumentEl.Call("getElementById", "prefab-list").Get("value").String()
+		if err := game.PlacePrefab(name); err != nil {
+			js.Global().Call("alert", err.Error())
+		}
+
+		return nil
+	})
+
 	exportGame := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		gl.DocumentEl.Call("getElementById", "import-export-val").Set("value", game.ExportAsJSON())
 
@@ -170,9 +495,42 @@ func main() {
 	importGame := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
 		gameData := gl.DocumentEl.Call("getElementById", "import-export-val").Get("value")
 
+		// a bad level shouldn't take down the game - just report what's wrong with it
 		if err := game.ImportFromJSON(gameData.String()); err != nil {
+			js.Global().Call("alert", err.Error())
+			return nil
+		}
+
+		if err := game.StartRecording(); err != nil {
 			panic(err)
 		}
+		startRenderLoop()
+
+		return nil
+	})
+
+	exportReplay := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		if replay := game.Recording(); replay != nil {
+			gl.DocumentEl.Call("getElementById", "import-export-val").Set("value", replay.ExportAsJSON())
+		}
+
+		return nil
+	})
+
+	playReplay := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		replayData := gl.DocumentEl.Call("getElementById", "import-export-val").Get("value")
+
+		replay, err := core.ImportReplayFromJSON(replayData.String())
+		if err != nil {
+			panic(err)
+		}
+
+		if err := game.StartReplay(replay); err != nil {
+			panic(err)
+		}
+
+		// the render loop stops when the game ends so it may need restarting
+		startRenderLoop()
 
 		return nil
 	})
@@ -202,21 +560,62 @@ func main() {
 	defer onKeyDown.Release()
 	defer onKeyUp.Release()
 	defer onCanvasResize.Release()
+	defer onCanvasMouseDown.Release()
 	defer exportGame.Release()
 	defer importGame.Release()
+	defer exportReplay.Release()
+	defer playReplay.Release()
+	defer movePlayerTo.Release()
+	defer nextLevel.Release()
+	defer undoEdit.Release()
+	defer redoEdit.Release()
+	defer cycleGridSize.Release()
+	defer quantizeLevel.Release()
+	defer savePrefab.Release()
+	defer placePrefab.Release()
 
-	js.Global().Call("requestAnimationFrame", renderFrame)
 	js.Global().Call("addEventListener", "keydown", onKeyDown)
 	js.Global().Call("addEventListener", "keyup", onKeyUp)
 	js.Global().Call("addEventListener", "resize", onCanvasResize)
+	gl.CanvasEl.Call("addEventListener", "mousedown", onCanvasMouseDown)
 	js.Global().Set("exportGame", exportGame)
 	js.Global().Set("importGame", importGame)
+	js.Global().Set("exportReplay", exportReplay)
+	js.Global().Set("playReplay", playReplay)
 	js.Global().Set("movePlayerTo", movePlayerTo)
+	js.Global().Set("nextLevel", nextLevel)
+	js.Global().Set("undoEdit", undoEdit)
+	js.Global().Set("redoEdit", redoEdit)
+	js.Global().Set("cycleGridSize", cycleGridSize)
+	js.Global().Set("quantizeLevel", quantizeLevel)
+	js.Global().Set("savePrefab", savePrefab)
+	js.Global().Set("placePrefab", placePrefab)
+
+	// a different level pack can be played with ?pack=<url>
+	levelPackRef := defaultLevelPackURL
+	if pack := js.Global().Get("URLSearchParams").New(js.Global().Get("location").Get("search")).Call("get", "pack"); pack.Truthy() {
+		levelPackRef = pack.String()
+	}
+
+	levelPackURL, err = resolveURL(js.Global().Get("location").Get("href").String(), levelPackRef)
+	if err != nil {
+		panic(err)
+	}
+
+	levelPackJSON, err := fetch(levelPackURL)
+	if err != nil {
+		panic(err)
+	}
+
+	levelPack, err = core.ImportLevelPackFromJSON(levelPackJSON)
+	if err != nil {
+		panic(err)
+	}
 
-	defaultMap := "{\"player\":{\"position\":[17.0, 5.0, 17.0],\"dimensions\":[1,1,1]},\"world\":[{\"position\":[0,0,0],\"dimensions\":[30,0.5,30]},{\"position\":[0,0,0],\"dimensions\":[1,5,31]},{\"position\":[1,0,0],\"dimensions\":[29,5,1]},{\"position\":[30,0,0],\"dimensions\":[1,5,31]},{\"position\":[1,0,30],\"dimensions\":[29,5,1]},{\"position\":[15,0,15],\"dimensions\":[5,3,5]},{\"position\":[19.333858,4.663249,10.518786],\"dimensions\":[2.3844757,0.9663763,3.4307919]},{\"position\":[19.81797,8.043748,16.667824],\"dimensions\":[3.157837,0.5,3.0037613]},{\"position\":[13.755774,10.925252,14.243277],\"dimensions\":[3.1519737,0.5,3.1608505]},{\"position\":[19.752327,13.4904995,14.212866],\"dimensions\":[3.1099472,0.5,3.5069046]},{\"position\":[13.141777,19.019375,17.493816],\"dimensions\":[3.5883484,0.5,3.2169342]},{\"position\":[17.71711,17.070627,17.4342],\"dimensions\":[1.3740082,0.5,1.8798332]},{\"position\":[9.9834385,19.7851,14.854664],\"dimensions\":[1.8013802,0.5,1.9732056]},{\"position\":[10.680285,20.484118,10.934053],\"dimensions\":[1.4222565,0.5,1.374588]},{\"position\":[10.817757,21.283535,5.738801],\"dimensions\":[1.3983421,0.5,1.9267006]},{\"position\":[11.764454,22.365986,0.5622523],\"dimensions\":[1.5518188,0.5,1.9198413]},{\"position\":[13.898621,25.346954,-4.2526617],\"dimensions\":[2.3314896,0.5,3.1563582]},{\"position\":[13.890339,27.095861,-19.547745],\"dimensions\":[0.47509003,0.5,13.060982]},{\"position\":[9.817467,29.427332,-28.7391],\"dimensions\":[5.102867,0.5,6.3680305]},{\"position\":[10.350336,31.758835,-33.195694],\"dimensions\":[2.472643,0.5,1.8093109]},{\"position\":[9.632517,33.24095,-38.226765],\"dimensions\":[2.1125278,0.5,2.8368073]},{\"position\":[7.1313553,0.5,6.5342093],\"dimensions\":[3.1799088,6.0781703,2.6655798]},{\"position\":[6.7412844,0.5,22.67184],\"dimensions\":[1.9776316,6.810938,2.4174194]},{\"position\":[23.095049,0.5,20.4995],\"dimensions\":[1.9512405,7.0273113,2.0497665]},{\"position\":[23.919891,0.5,7.150091],\"dimensions\":[2.399582,7.560281,2.5389977]}],\"enemies\":[{\"position\":[25,2,5],\"dimensions\":[1,1,1]}]}"
-	if err := game.ImportFromJSON(defaultMap); err != nil {
+	if err := loadLevel(0); err != nil {
 		panic(err)
 	}
+	startRenderLoop()
 
 	done := make(chan struct{}, 0)
 	<-done
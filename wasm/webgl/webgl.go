@@ -72,7 +72,7 @@ func New(canvasID string) (*Context, error) {
 	gl.constants.float = gl.ctx.Get("FLOAT")
 	gl.constants.unsignedShort = gl.ctx.Get("UNSIGNED_SHORT")
 	gl.constants.triangles = gl.ctx.Get("TRIANGLES")
-	gl.constants.lines = gl.ctx.Get("GL_LINES")
+	gl.constants.lines = gl.ctx.Get("LINES")
 
 	// calculate Viewport
 	gl.UpdateViewport()